@@ -0,0 +1,363 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gliderlabs/ssh"
+	gossh "golang.org/x/crypto/ssh"
+	"golang.org/x/time/rate"
+)
+
+const (
+	banFingerprint = "fingerprint"
+	banIP          = "ip"
+	banCIDR        = "cidr"
+)
+
+var (
+	ErrTooManySessions = fmt.Errorf("too many open sessions, close one and try again")
+	ErrTooManyGuesses  = fmt.Errorf("slow down")
+)
+
+var (
+	// contextKeyFingerprint holds the SHA256 fingerprint of the public key a
+	// client authenticated with, or "" if it logged in without one.
+	contextKeyFingerprint = &struct{ name string }{"fingerprint"}
+	// contextKeyBanned is set when a client offered a banned key, so it
+	// cannot fall back to keyless authentication.
+	contextKeyBanned = &struct{ name string }{"banned"}
+)
+
+// Ban blocks clients by public key fingerprint, IP address or CIDR range.
+type Ban struct {
+	Kind    string
+	Value   string
+	Reason  string
+	Created time.Time
+}
+
+type Bans []Ban
+
+// parseBan works out the kind of ban from its value: SHA256:... is a key
+// fingerprint, a value with a slash is a CIDR range and anything else must
+// be an IP address.
+func parseBan(value, reason string) (Ban, error) {
+	ban := Ban{Value: value, Reason: reason}
+	switch {
+	case strings.HasPrefix(value, "SHA256:"):
+		ban.Kind = banFingerprint
+	case strings.Contains(value, "/"):
+		_, ipnet, err := net.ParseCIDR(value)
+		if err != nil {
+			return ban, err
+		}
+		ban.Kind, ban.Value = banCIDR, ipnet.String()
+	default:
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return ban, fmt.Errorf("%q is not a key fingerprint, IP address or CIDR range", value)
+		}
+		ban.Kind, ban.Value = banIP, ip.String()
+	}
+
+	return ban, nil
+}
+
+// MatchIP returns the ban covering ip, if any.
+func (bans Bans) MatchIP(ip net.IP) (Ban, bool) {
+	for _, ban := range bans {
+		switch ban.Kind {
+		case banIP:
+			if banned := net.ParseIP(ban.Value); banned != nil && banned.Equal(ip) {
+				return ban, true
+			}
+		case banCIDR:
+			if _, ipnet, err := net.ParseCIDR(ban.Value); err == nil && ipnet.Contains(ip) {
+				return ban, true
+			}
+		}
+	}
+	return Ban{}, false
+}
+
+// MatchFingerprint returns the ban on the key fingerprint, if any.
+func (bans Bans) MatchFingerprint(fingerprint string) (Ban, bool) {
+	for _, ban := range bans {
+		if ban.Kind == banFingerprint && ban.Value == fingerprint {
+			return ban, true
+		}
+	}
+	return Ban{}, false
+}
+
+// keyedLimiter is a set of token bucket limiters, one per key, each allowing
+// perMinute events a minute with bursts of up to perMinute.
+type keyedLimiter struct {
+	mu        sync.Mutex
+	perMinute int
+	limiters  map[string]*keyedLimiterEntry
+	lastPrune time.Time
+}
+
+type keyedLimiterEntry struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// newKeyedLimiter returns a limiter allowing perMinute events per key, or
+// nil (which allows everything) if perMinute is zero.
+func newKeyedLimiter(perMinute int) *keyedLimiter {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &keyedLimiter{
+		perMinute: perMinute,
+		limiters:  make(map[string]*keyedLimiterEntry),
+		lastPrune: time.Now(),
+	}
+}
+
+func (k *keyedLimiter) Allow(key string) bool {
+	if k == nil {
+		return true
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	now := time.Now()
+	k.prune(now)
+
+	entry, ok := k.limiters[key]
+	if !ok {
+		entry = &keyedLimiterEntry{
+			limiter: rate.NewLimiter(rate.Every(time.Minute/time.Duration(k.perMinute)), k.perMinute),
+		}
+		k.limiters[key] = entry
+	}
+	entry.lastSeen = now
+
+	return entry.limiter.AllowN(now, 1)
+}
+
+// prune drops limiters idle long enough to have refilled completely.
+func (k *keyedLimiter) prune(now time.Time) {
+	if now.Sub(k.lastPrune) < time.Minute {
+		return
+	}
+	for key, entry := range k.limiters {
+		if now.Sub(entry.lastSeen) > time.Minute {
+			delete(k.limiters, key)
+		}
+	}
+	k.lastPrune = now
+}
+
+// abuseGuard enforces the configured rate limits and the ban list.
+type abuseGuard struct {
+	repo      *sqliteRepo
+	limits    RateLimits
+	connsByIP *keyedLimiter
+	connsByID *keyedLimiter
+	guesses   *keyedLimiter
+}
+
+func newAbuseGuard(repo *sqliteRepo, limits RateLimits) *abuseGuard {
+	return &abuseGuard{
+		repo:      repo,
+		limits:    limits,
+		connsByIP: newKeyedLimiter(limits.ConnectionsPerMinute),
+		connsByID: newKeyedLimiter(limits.IdentityConnectionsPerMinute),
+		guesses:   newKeyedLimiter(limits.GuessesPerMinute),
+	}
+}
+
+// ConnCallback drops connections from banned addresses or from addresses
+// connecting too often, before the SSH handshake.
+func (g *abuseGuard) ConnCallback(ctx ssh.Context, conn net.Conn) net.Conn {
+	ip := remoteIP(conn.RemoteAddr())
+
+	bans, err := g.repo.ListBans(ctx)
+	if err != nil {
+		logs.Error("list_bans_failed", "err", err)
+	} else if ban, ok := bans.MatchIP(ip); ok {
+		g.reject("banned", "remote", conn.RemoteAddr().String(), "ban", ban.Value)
+		return nil
+	}
+
+	if !g.connsByIP.Allow(ip.String()) {
+		g.reject("ip_rate", "remote", conn.RemoteAddr().String())
+		return nil
+	}
+
+	return conn
+}
+
+// authContext is the part of ssh.Context the authentication handlers use.
+type authContext interface {
+	context.Context
+	SetValue(key, value interface{})
+	RemoteAddr() net.Addr
+}
+
+// PublicKeyHandler accepts any key that is not banned and remembers its
+// fingerprint for the session.
+func (g *abuseGuard) PublicKeyHandler(ctx ssh.Context, key ssh.PublicKey) bool {
+	return g.acceptKey(ctx, gossh.FingerprintSHA256(key))
+}
+
+// acceptKey accepts the first key a client offers that is not banned, and
+// refuses any other key on the connection. x/crypto asks about keys before
+// the client proves it holds them and caches the answers, so the key a
+// client authenticates with need not be the last one asked about; with one
+// key accepted, the fingerprint kept is that of the only key that can
+// authenticate.
+func (g *abuseGuard) acceptKey(ctx authContext, fingerprint string) bool {
+	bans, err := g.repo.ListBans(ctx)
+	if err != nil {
+		logs.Error("list_bans_failed", "err", err)
+	} else if ban, ok := bans.MatchFingerprint(fingerprint); ok {
+		g.reject("banned", "remote", ctx.RemoteAddr().String(), "ban", ban.Value)
+		ctx.SetValue(contextKeyBanned, true)
+		return false
+	}
+
+	if accepted, ok := ctx.Value(contextKeyFingerprint).(string); ok && accepted != fingerprint {
+		g.reject("second_key", "remote", ctx.RemoteAddr().String(), "key", fingerprint)
+		return false
+	}
+	ctx.SetValue(contextKeyFingerprint, fingerprint)
+	return true
+}
+
+// KeyboardInteractiveHandler lets clients without a key play, unless they
+// already offered a banned key on this connection.
+func (g *abuseGuard) KeyboardInteractiveHandler(ctx ssh.Context, _ gossh.KeyboardInteractiveChallenge) bool {
+	return g.acceptKeyless(ctx)
+}
+
+// acceptKeyless clears the fingerprint of any key the client was asked
+// about, since it logged in without proving it holds one.
+func (g *abuseGuard) acceptKeyless(ctx authContext) bool {
+	if banned, _ := ctx.Value(contextKeyBanned).(bool); banned {
+		return false
+	}
+	ctx.SetValue(contextKeyFingerprint, "")
+	return true
+}
+
+// AdmitSession checks the per-identity connection rate and the cap on
+// concurrent sessions for the player of ls, and registers it in sessions.
+// It returns a function that deregisters the session.
+func (g *abuseGuard) AdmitSession(ls *liveSession, sessions *sessionRegistry) (func(), error) {
+	if !g.connsByID.Allow(ls.User) {
+		g.reject("identity_rate", "player", ls.User)
+		return nil, fmt.Errorf("too many connections, try again in a minute")
+	}
+	remove, err := sessions.Add(ls, g.limits.SessionsPerPlayer)
+	if err != nil {
+		g.reject("sessions", "player", ls.User)
+		return nil, err
+	}
+	return remove, nil
+}
+
+// AllowGuess returns ErrTooManyGuesses if user is guessing too fast.
+func (g *abuseGuard) AllowGuess(user string) error {
+	if !g.guesses.Allow(user) {
+		metricRejectedGuesses.WithLabelValues("rate_limited").Inc()
+		return ErrTooManyGuesses
+	}
+	return nil
+}
+
+func (g *abuseGuard) reject(reason string, kv ...interface{}) {
+	metricRejectedConnections.WithLabelValues(reason).Inc()
+	logs.Warn("connection_rejected", append([]interface{}{"reason", reason}, kv...)...)
+}
+
+func fingerprintOf(ctx context.Context) string {
+	fingerprint, _ := ctx.Value(contextKeyFingerprint).(string)
+	return fingerprint
+}
+
+func remoteIP(addr net.Addr) net.IP {
+	if tcp, ok := addr.(*net.TCPAddr); ok {
+		return tcp.IP
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return nil
+	}
+	return net.ParseIP(host)
+}
+
+// runBan implements the `wordle ban` subcommand for managing the ban list.
+func runBan(ctx context.Context, repo *sqliteRepo, args []string) error {
+	const usage = "usage: wordle ban list | add [-reason text] <fingerprint|ip|cidr> | remove <value>"
+	if len(args) == 0 {
+		return errors.New(usage)
+	}
+
+	switch args[0] {
+	case "list":
+		bans, err := repo.ListBans(ctx)
+		if err != nil {
+			return err
+		}
+		for _, ban := range bans {
+			fmt.Printf("%-12s %-50s %s %s\n", ban.Kind, ban.Value, ban.Created.Format("2006-01-02"), ban.Reason)
+		}
+		return nil
+
+	case "add":
+		var (
+			flags  = flag.NewFlagSet("ban add", flag.ExitOnError)
+			reason = flags.String("reason", "", "why the ban was added")
+		)
+		flags.Parse(args[1:])
+		if flags.NArg() != 1 {
+			return errors.New(usage)
+		}
+
+		ban, err := parseBan(flags.Arg(0), *reason)
+		if err != nil {
+			return err
+		}
+		if err := repo.AddBan(ctx, ban); err != nil {
+			return err
+		}
+		repo.Audit(ctx, auditEvent{Event: auditAdmin, User: "cli", Detail: fmt.Sprintf("ban %s %s", ban.Kind, ban.Value)})
+		fmt.Printf("banned %s %s\n", ban.Kind, ban.Value)
+		return nil
+
+	case "remove":
+		if len(args) != 2 {
+			return errors.New(usage)
+		}
+		// Bans are stored the way parseBan writes them.
+		ban, err := parseBan(args[1], "")
+		if err != nil {
+			return err
+		}
+		removed, err := repo.RemoveBan(ctx, ban.Value)
+		if err != nil {
+			return err
+		}
+		if !removed {
+			return fmt.Errorf("%s is not banned", ban.Value)
+		}
+		repo.Audit(ctx, auditEvent{Event: auditAdmin, User: "cli", Detail: fmt.Sprintf("unban %s", ban.Value)})
+		fmt.Printf("unbanned %s\n", ban.Value)
+		return nil
+	}
+
+	return errors.New(usage)
+}
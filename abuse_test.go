@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBan(t *testing.T) {
+	var tests = []struct {
+		Value string
+		Kind  string
+		Norm  string
+	}{
+		{"SHA256:abc", banFingerprint, "SHA256:abc"},
+		{"10.1.2.3", banIP, "10.1.2.3"},
+		{"10.1.2.3/16", banCIDR, "10.1.0.0/16"},
+		{"::1", banIP, "::1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Value, func(t *testing.T) {
+			ban, err := parseBan(tt.Value, "")
+			require.NoError(t, err)
+			assert.Equal(t, tt.Kind, ban.Kind)
+			assert.Equal(t, tt.Norm, ban.Value)
+		})
+	}
+
+	_, err := parseBan("bob", "")
+	assert.Error(t, err)
+}
+
+func TestBansMatch(t *testing.T) {
+	bans := Bans{
+		{Kind: banIP, Value: "192.168.1.10"},
+		{Kind: banCIDR, Value: "10.0.0.0/8"},
+		{Kind: banFingerprint, Value: "SHA256:abc"},
+	}
+
+	_, ok := bans.MatchIP(net.ParseIP("192.168.1.10"))
+	assert.True(t, ok)
+	_, ok = bans.MatchIP(net.ParseIP("10.20.30.40"))
+	assert.True(t, ok)
+	_, ok = bans.MatchIP(net.ParseIP("192.168.1.11"))
+	assert.False(t, ok)
+
+	_, ok = bans.MatchFingerprint("SHA256:abc")
+	assert.True(t, ok)
+	_, ok = bans.MatchFingerprint("SHA256:def")
+	assert.False(t, ok)
+}
+
+func TestKeyedLimiter(t *testing.T) {
+	limiter := newKeyedLimiter(2)
+	assert.True(t, limiter.Allow("a"))
+	assert.True(t, limiter.Allow("a"))
+	assert.False(t, limiter.Allow("a"))
+	assert.True(t, limiter.Allow("b"))
+
+	var unlimited *keyedLimiter = newKeyedLimiter(0)
+	for i := 0; i < 100; i++ {
+		assert.True(t, unlimited.Allow("a"))
+	}
+}
+
+// fakeAuthContext stands in for the ssh.Context of a connection being
+// authenticated.
+type fakeAuthContext struct {
+	context.Context
+	values map[interface{}]interface{}
+}
+
+func (c *fakeAuthContext) Value(key interface{}) interface{} {
+	if v, ok := c.values[key]; ok {
+		return v
+	}
+	return c.Context.Value(key)
+}
+
+func (c *fakeAuthContext) SetValue(key, value interface{}) { c.values[key] = value }
+
+func (c *fakeAuthContext) RemoteAddr() net.Addr {
+	return &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 2222}
+}
+
+func TestUnbanAsBanned(t *testing.T) {
+	ctx := context.Background()
+	repo, err := newRepo(filepath.Join(t.TempDir(), "wordle.db"), StreakConfig{})
+	require.NoError(t, err)
+	defer repo.Close()
+	admin := newAdminConsole(repo, newSessionRegistry(), nil)
+
+	// Bans are removed with the text they were added with, however it was
+	// normalized.
+	require.NoError(t, runBan(ctx, repo, []string{"add", "203.0.113.7/24"}))
+	require.NoError(t, runBan(ctx, repo, []string{"remove", "203.0.113.7/24"}))
+	assert.Error(t, runBan(ctx, repo, []string{"remove", "203.0.113.7/24"}))
+
+	out := &fakeSession{}
+	v := &view{locale: defaultLocale, s: out}
+	assert.Equal(t, 0, admin.banCommand(ctx, v, "root", []string{"2001:DB8::1"}))
+	assert.Equal(t, 0, admin.unbanCommand(ctx, v, "root", []string{"2001:DB8::1"}))
+	assert.Contains(t, out.out.String(), "unbanned 2001:db8::1")
+
+	bans, err := repo.ListBans(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, bans)
+}
+
+func TestAuthenticationFingerprint(t *testing.T) {
+	repo, err := newRepo(filepath.Join(t.TempDir(), "wordle.db"), StreakConfig{})
+	require.NoError(t, err)
+	defer repo.Close()
+	require.NoError(t, repo.AddBan(context.Background(), Ban{Kind: banFingerprint, Value: "SHA256:banned"}))
+	guard := newAbuseGuard(repo, RateLimits{})
+	admin := newAdminConsole(repo, newSessionRegistry(), []string{"SHA256:admin"})
+	newCtx := func() *fakeAuthContext {
+		return &fakeAuthContext{Context: context.Background(), values: map[interface{}]interface{}{}}
+	}
+
+	// Only the first key asked about can authenticate.
+	ctx := newCtx()
+	assert.True(t, guard.acceptKey(ctx, "SHA256:admin"))
+	assert.False(t, guard.acceptKey(ctx, "SHA256:mine"))
+	assert.True(t, guard.acceptKey(ctx, "SHA256:admin"))
+	assert.True(t, admin.IsAdmin(ctx))
+
+	// Offering the admin's key and then logging in without one gains
+	// nothing.
+	ctx = newCtx()
+	assert.True(t, guard.acceptKey(ctx, "SHA256:admin"))
+	assert.True(t, guard.acceptKeyless(ctx))
+	assert.Equal(t, "", fingerprintOf(ctx))
+	assert.False(t, admin.IsAdmin(ctx))
+
+	// A banned key is refused, and so is logging in without one after it,
+	// but another key is not.
+	ctx = newCtx()
+	assert.False(t, guard.acceptKey(ctx, "SHA256:banned"))
+	assert.False(t, guard.acceptKeyless(ctx))
+	assert.True(t, guard.acceptKey(ctx, "SHA256:mine"))
+	assert.Equal(t, "SHA256:mine", fingerprintOf(ctx))
+}
+
+func TestAdmitSession(t *testing.T) {
+	guard := newAbuseGuard(nil, RateLimits{SessionsPerPlayer: 2})
+	sessions := newSessionRegistry()
+
+	// Connections arriving together still get in only up to the cap.
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		removes []func()
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			remove, err := guard.AdmitSession(&liveSession{ID: fmt.Sprint(i), User: "alice"}, sessions)
+			if err != nil {
+				assert.Equal(t, ErrTooManySessions, err)
+				return
+			}
+			mu.Lock()
+			removes = append(removes, remove)
+			mu.Unlock()
+		}(i)
+	}
+	wg.Wait()
+	require.Len(t, removes, 2)
+	assert.Equal(t, 2, sessions.CountUser("alice"))
+
+	removes[0]()
+	_, err := guard.AdmitSession(&liveSession{ID: "again", User: "alice"}, sessions)
+	assert.NoError(t, err)
+	_, err = guard.AdmitSession(&liveSession{ID: "bob", User: "bob"}, sessions)
+	assert.NoError(t, err)
+}
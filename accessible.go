@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The accessible mode is meant for screen readers: it never clears the
+// screen, moves the cursor or relies on colour, and describes the board in
+// plain sentences instead of drawing it.
+
+// summaryCommand is typed at the prompt to hear what is known so far.
+const summaryCommand = "?"
+
+// setAccessible switches the view to the accessible line mode. It must be
+// called before anything is drawn.
+func (v *view) setAccessible() {
+	v.accessible = true
+	if v.tui {
+		v.tui = false
+		v.write(showCursor)
+	}
+	v.term.SetPrompt("> ")
+}
+
+// announce prints the guesses the player has not heard yet, introducing the
+// game the first time it is called.
+func announce(v *view, game *Game) {
+	v.flash(func() {
+		if !v.introduced {
+			v.introduced = true
+			print(v, v.t("accessible_intro", WordLength, MaxGuesses, summaryCommand, calendarShortcut)+"\n")
+		}
+		for ; v.announced < len(game.Guesses); v.announced++ {
+			print(v, describeGuess(v, game, v.announced)+"\n")
+		}
+		if !game.IsDone() {
+			print(v, v.t("guesses_left", v.plural(MaxGuesses-len(game.Guesses), "guesses"))+"\n")
+		}
+	})
+}
+
+// describeGuess reads out guess i letter by letter, e.g.
+// "Guess 2, crane: C absent, R present, A correct, N absent, E absent."
+func describeGuess(v *view, game *Game, i int) string {
+	var (
+		word  = game.Guesses[i]
+		parts = make([]string, 0, WordLength)
+		names = map[letterState]string{letterCorrect: "letter_correct", letterPresent: "letter_present", letterAbsent: "letter_absent"}
+	)
+	letters := []rune(word)
+	for j, state := range game.Score(word) {
+		parts = append(parts, v.t(names[state], upper(letters[j])))
+	}
+	return v.t("describe_guess", i+1, word, strings.Join(parts, ", "))
+}
+
+// summarize describes every constraint the guesses so far put on the answer.
+func summarize(v *view, game *Game) string {
+	if len(game.Guesses) == 0 {
+		return v.t("no_guesses_yet", v.plural(MaxGuesses, "guesses"))
+	}
+
+	var (
+		placed   = make(map[int]rune)
+		notPlace = make(map[rune][]int)
+		absent   = make(map[rune]bool)
+	)
+	for _, word := range game.Guesses {
+		letters := []rune(word)
+		for i, state := range game.Score(word) {
+			c := letters[i]
+			switch state {
+			case letterCorrect:
+				placed[i] = c
+			case letterPresent:
+				notPlace[c] = appendUnique(notPlace[c], i)
+			case letterAbsent:
+				absent[c] = true
+			}
+		}
+	}
+
+	var sentences []string
+
+	if len(placed) > 0 {
+		var parts []string
+		for i := 0; i < WordLength; i++ {
+			if c, ok := placed[i]; ok {
+				parts = append(parts, v.t("letter_at", upper(c), translateNth(v.locale, "ordinals", i)))
+			}
+		}
+		sentences = append(sentences, v.t("in_place", strings.Join(parts, ", ")))
+	}
+
+	var present []string
+	for c, positions := range notPlace {
+		if placedAnywhere(placed, c) {
+			continue
+		}
+		sort.Ints(positions)
+		var not []string
+		for _, i := range positions {
+			not = append(not, translateNth(v.locale, "ordinals", i))
+		}
+		present = append(present, v.t("letter_not_at", upper(c), strings.Join(not, v.t("or"))))
+	}
+	if len(present) > 0 {
+		sort.Strings(present)
+		sentences = append(sentences, v.t("not_placed", strings.Join(present, "; ")))
+	}
+
+	if len(absent) > 0 {
+		var letters []string
+		for c := range absent {
+			letters = append(letters, string(upper(c)))
+		}
+		sort.Strings(letters)
+		sentences = append(sentences, v.t("not_in_word", strings.Join(letters, ", ")))
+	}
+
+	sentences = append(sentences, v.t("guesses_left", v.plural(MaxGuesses-len(game.Guesses), "guesses")))
+	return strings.Join(sentences, " ")
+}
+
+// describeStats presents the statistics as sentences.
+func describeStats(v *view, stats PlayerStats) string {
+	sentences := []string{
+		v.t("played_and_won", v.plural(stats.Played, "games"), stats.WinPercent()),
+		v.t("streaks", stats.Streak(time.Now()), stats.MaxStreak),
+	}
+	if stats.Freezes > 0 {
+		sentences = append(sentences, v.t("have_freezes", v.plural(stats.Freezes, "freezes")))
+	}
+
+	var wins []string
+	for i, n := range stats.Distribution {
+		if n > 0 {
+			wins = append(wins, v.t("games_in", v.plural(n, "games"), v.plural(i+1, "guesses")))
+		}
+	}
+	if len(wins) > 0 {
+		sentences = append(sentences, v.t("you_won", strings.Join(wins, ", ")))
+	}
+
+	var (
+		now   = time.Now()
+		hours = (24 - now.Hour()) - 1
+		mins  = 60 - now.Minute()
+	)
+	sentences = append(sentences, v.t("next_wordle_in", v.plural(hours, "hours"), v.plural(mins, "minutes")))
+
+	return strings.Join(sentences, " ")
+}
+
+// describePuzzle tells the player how everyone did on today's puzzle.
+func describePuzzle(v *view, game *Game, today PuzzleStats) string {
+	percentile, ok := today.Percentile(*game)
+	if !ok {
+		return v.t("first_to_finish")
+	}
+	return v.t("describe_puzzle", v.plural(today.Played, "players"), today.WinPercent(), today.AverageGuesses(), percentile)
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, one)
+	}
+	return fmt.Sprintf("%d %s", n, many)
+}
+
+func upper(c rune) rune {
+	return []rune(strings.ToUpper(string(c)))[0]
+}
+
+func placedAnywhere(placed map[int]rune, c rune) bool {
+	for _, p := range placed {
+		if p == c {
+			return true
+		}
+	}
+	return false
+}
+
+func appendUnique(list []int, i int) []int {
+	for _, v := range list {
+		if v == i {
+			return list
+		}
+	}
+	return append(list, i)
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescribeGuess(t *testing.T) {
+	v := &view{locale: defaultLocale}
+	game := NewGame("juice")
+	game.Guesses = []string{"crane"}
+
+	assert.Equal(t, "Guess 1, crane: C present, R absent, A absent, N absent, E correct.", describeGuess(v, game, 0))
+}
+
+func TestSummarize(t *testing.T) {
+	v := &view{locale: defaultLocale}
+	game := NewGame("juice")
+	assert.Equal(t, "No guesses yet. 6 guesses left.", summarize(v, game))
+
+	game.Guesses = []string{"crane", "slice"}
+	assert.Equal(t,
+		"In place: I third, C fourth, E fifth. Not in the word: A, L, N, R, S. 4 guesses left.",
+		summarize(v, game),
+	)
+}
@@ -0,0 +1,394 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/gliderlabs/ssh"
+)
+
+const adminUsage = `usage: ssh <host> admin <command>
+
+commands:
+    sessions                              list connected players
+    kick <session|player>                 disconnect a player
+    ban [-reason text] <session|fingerprint|ip|cidr>
+                                          ban a player's key, or an address
+    unban <fingerprint|ip|cidr>           lift a ban
+    stats                                 show server statistics
+    reset <session|player>                let a player start today's game again
+    merge <from-player> <into-player>     move one player's games to another
+    broadcast [-for 10m] <message>        send a message to every player, and
+                                          to those who connect in the next 10m
+    schedule [-reveal]                    list the scheduled answers
+    schedule [-lang code] <date> <word>   set the answer for a day, e.g. 2026-11-01
+    unschedule [-lang code] <date>        remove a scheduled answer
+
+Sessions can be given by a prefix of their ID and players by the name shown
+in the sessions list.
+`
+
+// kickTimeout is how long reset waits for a kicked player's sessions to end.
+const kickTimeout = 10 * time.Second
+
+// adminConsole runs `ssh host admin ...` for the players whose keys are
+// listed as admins in the config.
+type adminConsole struct {
+	repo     *sqliteRepo
+	sessions *sessionRegistry
+	admins   []string
+	started  time.Time
+}
+
+func newAdminConsole(repo *sqliteRepo, sessions *sessionRegistry, admins []string) *adminConsole {
+	return &adminConsole{repo: repo, sessions: sessions, admins: admins, started: time.Now()}
+}
+
+// IsAdmin reports whether the client authenticated with an admin's key.
+// Clients that logged in without a key have no fingerprint, so are never
+// admins whichever keys they offered first.
+func (a *adminConsole) IsAdmin(ctx context.Context) bool {
+	fingerprint := fingerprintOf(ctx)
+	return fingerprint != "" && contains(a.admins, fingerprint)
+}
+
+// Run handles an admin command and returns the exit status for the session.
+func (a *adminConsole) Run(ctx context.Context, v *view, user string, args []string) int {
+	if !a.IsAdmin(ctx) {
+		logs.Warn("admin_denied", "player", user, "key", fingerprintOf(ctx))
+		print(v, "permission denied\n")
+		return 1
+	}
+	if len(args) == 0 {
+		print(v, adminUsage)
+		return 2
+	}
+
+	switch args[0] {
+	case "sessions":
+		return a.sessionsCommand(v)
+	case "kick":
+		return a.kickCommand(ctx, v, user, args[1:])
+	case "ban":
+		return a.banCommand(ctx, v, user, args[1:])
+	case "unban":
+		return a.unbanCommand(ctx, v, user, args[1:])
+	case "stats":
+		return a.statsCommand(ctx, v)
+	case "reset":
+		return a.resetCommand(ctx, v, user, args[1:])
+	case "merge":
+		return a.mergeCommand(ctx, v, user, args[1:])
+	case "broadcast":
+		return a.broadcastCommand(ctx, v, user, args[1:])
+	case "schedule":
+		return a.scheduleCommand(ctx, v, user, args[1:])
+	case "unschedule":
+		return a.unscheduleCommand(ctx, v, user, args[1:])
+	case "help":
+		print(v, adminUsage)
+		return 0
+	}
+
+	print(v, fmt.Sprintf("unknown admin command %q\n\n%s", args[0], adminUsage))
+	return 1
+}
+
+func (a *adminConsole) sessionsCommand(v *view) int {
+	list := a.sessions.List()
+	if len(list) == 0 {
+		print(v, "no players are connected\n")
+		return 0
+	}
+	for _, ls := range list {
+		key := ls.Key
+		if key == "" {
+			key = "(no key)"
+		}
+		print(v, fmt.Sprintf("%-12s %-24s %-5s %8s  %s\n",
+			shortID(ls.ID), ls.User, ls.Progress(), time.Since(ls.Started).Round(time.Second), key))
+	}
+	return 0
+}
+
+func (a *adminConsole) kickCommand(ctx context.Context, v *view, user string, args []string) int {
+	if len(args) != 1 {
+		print(v, adminUsage)
+		return 2
+	}
+
+	kicked := len(a.kick(ctx, args[0], "You have been disconnected by an admin."))
+	if kicked == 0 {
+		print(v, fmt.Sprintf("no session or player matches %q\n", args[0]))
+		return 1
+	}
+	a.audit(ctx, user, auditAdmin, fmt.Sprintf("kick %s", args[0]))
+	logs.Info("admin_kick", "player", user, "target", args[0], "sessions", kicked)
+
+	print(v, fmt.Sprintf("kicked %s\n", plural(kicked, "session", "sessions")))
+	return 0
+}
+
+// kick disconnects the sessions matching target, a session ID prefix or a
+// player, other than the admin's own session on ctx, and returns them.
+func (a *adminConsole) kick(ctx context.Context, target, reason string) []*liveSession {
+	var kicked []*liveSession
+	for _, ls := range a.match(target) {
+		if ls.ID == sessionIDOf(ctx) {
+			continue
+		}
+		ls.Kick(reason)
+		kicked = append(kicked, ls)
+	}
+	return kicked
+}
+
+// match returns the live sessions whose ID starts with target, or else the
+// sessions of the player called target.
+func (a *adminConsole) match(target string) []*liveSession {
+	var byID, byUser []*liveSession
+	for _, ls := range a.sessions.List() {
+		if ls.User == target {
+			byUser = append(byUser, ls)
+		}
+		if len(target) >= 4 && strings.HasPrefix(ls.ID, target) {
+			byID = append(byID, ls)
+		}
+	}
+	if len(byID) > 0 {
+		return byID
+	}
+	return byUser
+}
+
+// resolvePlayer returns the player of the session target names, or target
+// itself if it names no session.
+func (a *adminConsole) resolvePlayer(target string) string {
+	if matched := a.match(target); len(matched) > 0 {
+		return matched[0].User
+	}
+	return target
+}
+
+func (a *adminConsole) banCommand(ctx context.Context, v *view, user string, args []string) int {
+	var (
+		flags  = flag.NewFlagSet("ban", flag.ContinueOnError)
+		reason = flags.String("reason", "", "why the ban was added")
+	)
+	flags.SetOutput(viewWriter{v})
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		print(v, adminUsage)
+		return 2
+	}
+
+	// A connected player is banned by their key, or their address if they
+	// have none.
+	value := flags.Arg(0)
+	if matched := a.match(value); len(matched) > 0 {
+		value = matched[0].Key
+		if value == "" {
+			value = sessionIP(matched[0]).String()
+		}
+	}
+
+	ban, err := parseBan(value, *reason)
+	if err != nil {
+		print(v, err.Error()+"\n")
+		return 1
+	}
+	if err := a.repo.AddBan(ctx, ban); err != nil {
+		logs.Error("add_ban_failed", "err", err)
+		print(v, "failed to add the ban\n")
+		return 1
+	}
+	a.audit(ctx, user, auditAdmin, fmt.Sprintf("ban %s %s", ban.Kind, ban.Value))
+	logs.Info("admin_ban", "player", user, "ban", ban.Value)
+
+	bans := Bans{ban}
+	for _, ls := range a.sessions.List() {
+		_, byKey := bans.MatchFingerprint(ls.Key)
+		_, byIP := bans.MatchIP(sessionIP(ls))
+		if byKey || byIP {
+			ls.Kick("You have been banned.")
+		}
+	}
+
+	print(v, fmt.Sprintf("banned %s %s\n", ban.Kind, ban.Value))
+	return 0
+}
+
+func (a *adminConsole) unbanCommand(ctx context.Context, v *view, user string, args []string) int {
+	if len(args) != 1 {
+		print(v, adminUsage)
+		return 2
+	}
+
+	// Bans are stored the way parseBan writes them.
+	ban, err := parseBan(args[0], "")
+	if err != nil {
+		print(v, err.Error()+"\n")
+		return 1
+	}
+	removed, err := a.repo.RemoveBan(ctx, ban.Value)
+	if err != nil {
+		logs.Error("remove_ban_failed", "err", err)
+		print(v, "failed to remove the ban\n")
+		return 1
+	}
+	if !removed {
+		print(v, fmt.Sprintf("%s is not banned\n", ban.Value))
+		return 1
+	}
+	a.audit(ctx, user, auditAdmin, fmt.Sprintf("unban %s", ban.Value))
+	logs.Info("admin_unban", "player", user, "ban", ban.Value)
+
+	print(v, fmt.Sprintf("unbanned %s\n", ban.Value))
+	return 0
+}
+
+func (a *adminConsole) statsCommand(ctx context.Context, v *view) int {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	stats, err := a.repo.ServerStats(ctx, today)
+	if err != nil {
+		logs.Error("server_stats_failed", "err", err)
+		print(v, "failed to load statistics\n")
+		return 1
+	}
+
+	print(v, fmt.Sprintf("uptime           %s\n", time.Since(a.started).Round(time.Second)))
+	print(v, fmt.Sprintf("connected        %d\n", a.sessions.Len()))
+	print(v, fmt.Sprintf("players          %d\n", stats.Players))
+	print(v, fmt.Sprintf("games            %d\n", stats.Games))
+	print(v, fmt.Sprintf("games today      %d\n", stats.GamesSince))
+	print(v, fmt.Sprintf("bans             %d\n", stats.Bans))
+	return 0
+}
+
+func (a *adminConsole) resetCommand(ctx context.Context, v *view, user string, args []string) int {
+	if len(args) != 1 {
+		print(v, adminUsage)
+		return 2
+	}
+	player := a.resolvePlayer(args[0])
+
+	// A connected player would otherwise carry on, and save, the old game,
+	// so disconnect them and wait for their sessions to save and end before
+	// deleting anything.
+	kicked := a.kick(ctx, player, "An admin reset your game. Reconnect to play again.")
+	waitCtx, cancel := context.WithTimeout(ctx, kickTimeout)
+	defer cancel()
+	if err := a.sessions.WaitClosed(waitCtx, kicked); err != nil {
+		print(v, fmt.Sprintf("%s is still connected, try again\n", player))
+		return 1
+	}
+
+	games, err := a.repo.ListGames(ctx, player)
+	if err != nil {
+		logs.Error("list_games_failed", "err", err)
+		print(v, "failed to load games\n")
+		return 1
+	}
+
+	// Days start in UTC, like the word of the day.
+	today := time.Now().UTC().Format(dateLayout)
+	reset := 0
+	for _, game := range games {
+		if game.Started.UTC().Format(dateLayout) != today {
+			break
+		}
+		if err := a.repo.DeleteGame(ctx, game.ID); err != nil {
+			logs.Error("delete_game_failed", "game", game.ID, "err", err)
+			print(v, "failed to reset the game\n")
+			return 1
+		}
+		reset++
+	}
+	if reset == 0 {
+		print(v, fmt.Sprintf("%s has not played today\n", player))
+		return 1
+	}
+
+	a.audit(ctx, user, auditAdmin, fmt.Sprintf("reset %s", player))
+	logs.Info("admin_reset", "player", user, "target", player)
+
+	print(v, fmt.Sprintf("reset today's game for %s\n", player))
+	return 0
+}
+
+func (a *adminConsole) mergeCommand(ctx context.Context, v *view, user string, args []string) int {
+	if len(args) != 2 {
+		print(v, adminUsage)
+		return 2
+	}
+	from, into := args[0], args[1]
+	if from == into {
+		print(v, "cannot merge a player into themselves\n")
+		return 1
+	}
+	if a.sessions.CountUser(from) > 0 {
+		print(v, fmt.Sprintf("%s is connected, kick them first\n", from))
+		return 1
+	}
+
+	moved, err := a.repo.MergePlayers(ctx, from, into)
+	if err != nil {
+		logs.Error("merge_players_failed", "err", err)
+		print(v, "failed to merge the players\n")
+		return 1
+	}
+	a.audit(ctx, user, auditAdmin, fmt.Sprintf("merge %s %s", from, into))
+	logs.Info("admin_merge", "player", user, "from", from, "into", into, "games", moved)
+
+	print(v, fmt.Sprintf("moved %s from %s to %s\n", plural(int(moved), "game", "games"), from, into))
+	return 0
+}
+
+func (a *adminConsole) broadcastCommand(ctx context.Context, v *view, user string, args []string) int {
+	var (
+		flags = flag.NewFlagSet("broadcast", flag.ContinueOnError)
+		keep  = flags.Duration("for", 0, "how long to show the message to players who connect later")
+	)
+	flags.SetOutput(viewWriter{v})
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	message := strings.TrimSpace(strings.Join(flags.Args(), " "))
+	if message == "" {
+		print(v, adminUsage)
+		return 2
+	}
+
+	a.sessions.Announce(message, *keep)
+	a.audit(ctx, user, auditAdmin, "broadcast")
+	logs.Info("admin_broadcast", "player", user, "sessions", a.sessions.Len())
+
+	print(v, fmt.Sprintf("sent to %s\n", plural(a.sessions.Len(), "session", "sessions")))
+	return 0
+}
+
+// audit records an admin action against the session it came from.
+func (a *adminConsole) audit(ctx context.Context, user, event, detail string) {
+	ev := auditEvent{Event: event, Session: sessionIDOf(ctx), User: user, Detail: detail}
+	if addr, ok := ctx.Value(ssh.ContextKeyRemoteAddr).(net.Addr); ok {
+		ev.Remote = addr.String()
+	}
+	if err := a.repo.Audit(ctx, ev); err != nil {
+		logs.Error("audit_failed", "audit_event", event, "err", err)
+	}
+}
+
+// sessionIP returns the address a live session connected from.
+func sessionIP(ls *liveSession) net.IP {
+	host, _, err := net.SplitHostPort(ls.Remote)
+	if err != nil {
+		return nil
+	}
+	return net.ParseIP(host)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergePlayers(t *testing.T) {
+	repo, err := newRepo(filepath.Join(t.TempDir(), "wordle.db"), StreakConfig{})
+	require.NoError(t, err)
+	defer repo.Close()
+
+	ctx := context.Background()
+	for _, user := range []string{"old|10.0.0.1", "old|10.0.0.1", "new|10.0.0.2"} {
+		require.NoError(t, repo.SaveGame(ctx, user, NewGame("crane")))
+	}
+	require.NoError(t, repo.SaveSettings(ctx, "old|10.0.0.1", Settings{Theme: "mono"}))
+
+	moved, err := repo.MergePlayers(ctx, "old|10.0.0.1", "new|10.0.0.2")
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, moved)
+
+	games, err := repo.ListGames(ctx, "new|10.0.0.2")
+	require.NoError(t, err)
+	assert.Len(t, games, 3)
+
+	settings, err := repo.GetSettings(ctx, "new|10.0.0.2")
+	require.NoError(t, err)
+	assert.Equal(t, "mono", settings.Theme)
+
+	stats, err := repo.ServerStats(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, ServerStats{Players: 1, Games: 3, GamesSince: 3}, stats)
+}
+
+func TestMergePlayersSpeedruns(t *testing.T) {
+	repo, err := newRepo(filepath.Join(t.TempDir(), "wordle.db"), StreakConfig{})
+	require.NoError(t, err)
+	defer repo.Close()
+
+	var (
+		ctx = context.Background()
+		day = time.Date(2026, time.October, 1, 12, 0, 0, 0, time.UTC)
+	)
+	race := func(user string, started time.Time, elapsed time.Duration, won bool) {
+		game := NewGame("ultra")
+		game.Started = started
+		game.Clock = &Clock{Start: started, Splits: []time.Duration{elapsed}}
+		game.Guesses = []string{"crane"}
+		if won {
+			game.Guesses, game.Won = []string{"ultra"}, true
+		}
+		require.NoError(t, repo.SaveSpeedrun(ctx, user, game))
+	}
+	race("old", day, 20*time.Second, true)
+	race("new", day, 30*time.Second, true)
+	race("old", day.AddDate(0, 0, 1), 40*time.Second, true)
+	race("new", day.AddDate(0, 0, 1), 0, false)
+	race("old", day.AddDate(0, 0, 2), 0, false)
+	race("new", day.AddDate(0, 0, 2), 50*time.Second, true)
+	race("old", day.AddDate(0, 0, 3), 60*time.Second, true)
+
+	_, err = repo.MergePlayers(ctx, "old", "new")
+	require.NoError(t, err)
+
+	// The faster win of each day is kept, under the merged player.
+	for i, want := range []time.Duration{20 * time.Second, 40 * time.Second, 50 * time.Second, 60 * time.Second} {
+		puzzle := puzzleNumber(day.AddDate(0, 0, i))
+		game, err := repo.Speedrun(ctx, "new", defaultLocale, puzzle)
+		require.NoError(t, err)
+		require.NotNil(t, game, "day %d", i)
+		assert.Equal(t, want, game.Elapsed(), "day %d", i)
+
+		game, err = repo.Speedrun(ctx, "old", defaultLocale, puzzle)
+		require.NoError(t, err)
+		assert.Nil(t, game, "day %d", i)
+	}
+	fastest, err := repo.FastestSpeedruns(ctx, defaultLocale, puzzleNumber(day), leaderboardSize)
+	require.NoError(t, err)
+	assert.Equal(t, []SpeedrunTime{{User: "new", Puzzle: puzzleNumber(day), Elapsed: 20 * time.Second}}, fastest)
+}
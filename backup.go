@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Backups are consistent copies of the database taken with VACUUM INTO while
+// the server keeps running, named by the time they were taken so that they
+// sort oldest first.
+const (
+	backupPrefix = "wordle-"
+	backupSuffix = ".db"
+	backupLayout = "20060102T150405Z"
+)
+
+// Backup writes a consistent copy of the database to path, which must not
+// exist yet.
+func (r *sqliteRepo) Backup(ctx context.Context, path string) error {
+	defer observeRepo("backup", time.Now())
+
+	_, err := r.DB.ExecContext(ctx, `VACUUM INTO ?`, path)
+	return err
+}
+
+// PruneAbandonedGames removes unfinished games started before cutoff,
+// moving them to game_archive if archive is set. It returns how many games
+// were removed.
+func (r *sqliteRepo) PruneAbandonedGames(ctx context.Context, cutoff time.Time, archive bool) (int, error) {
+	defer observeRepo("prune_games", time.Now())
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// created_at is when the game was first saved, so only games that old
+	// can have been started before cutoff.
+	rows, err := tx.QueryContext(ctx, `SELECT id, data FROM game WHERE created_at < ?`, cutoff.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return 0, err
+	}
+	var abandoned []int64
+	for rows.Next() {
+		var (
+			id   int64
+			data []byte
+			game Game
+		)
+		if err := rows.Scan(&id, &data); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if err := json.Unmarshal(data, &game); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to decode game %d", id)
+		}
+		if !game.IsDone() && game.Started.Before(cutoff) {
+			abandoned = append(abandoned, id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, id := range abandoned {
+		if archive {
+			const copy = `INSERT OR REPLACE INTO game_archive(id, user, data, created_at) SELECT id, user, data, created_at FROM game WHERE id=?`
+			if _, err := tx.ExecContext(ctx, copy, id); err != nil {
+				return 0, err
+			}
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM game WHERE id=?`, id); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(abandoned), tx.Commit()
+}
+
+// backupTo takes a backup into dir and deletes all but the newest keep
+// backups there. It returns the path of the new backup.
+func backupTo(ctx context.Context, repo *sqliteRepo, dir string, keep int, now time.Time) (string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+
+	// Write under a temporary name so a failed backup is never rotated in.
+	path := filepath.Join(dir, backupPrefix+now.UTC().Format(backupLayout)+backupSuffix)
+	tmp := path + ".tmp"
+	os.Remove(tmp)
+	if err := repo.Backup(ctx, tmp); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return "", err
+	}
+
+	return path, rotateBackups(dir, keep)
+}
+
+// rotateBackups deletes all but the newest keep backups in dir.
+func rotateBackups(dir string, keep int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	var backups []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && strings.HasPrefix(name, backupPrefix) && strings.HasSuffix(name, backupSuffix) {
+			backups = append(backups, name)
+		}
+	}
+	sort.Strings(backups)
+
+	for len(backups) > keep {
+		if err := os.Remove(filepath.Join(dir, backups[0])); err != nil {
+			return err
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+// runMaintenance takes periodic backups and prunes abandoned games until ctx
+// is done.
+func runMaintenance(ctx context.Context, repo *sqliteRepo, backup BackupConfig, retention RetentionConfig) {
+	prune := func() {
+		if retention.AbandonedGameDays == 0 {
+			return
+		}
+		cutoff := time.Now().AddDate(0, 0, -retention.AbandonedGameDays)
+		n, err := repo.PruneAbandonedGames(ctx, cutoff, retention.Archive)
+		if err != nil {
+			logs.Error("prune_games_failed", "err", err)
+			return
+		}
+		if n > 0 {
+			logs.Info("games_pruned", "games", n, "archived", retention.Archive)
+		}
+	}
+
+	var backups <-chan time.Time
+	if backup.Dir != "" {
+		ticker := time.NewTicker(backup.Interval)
+		defer ticker.Stop()
+		backups = ticker.C
+	}
+	daily := time.NewTicker(24 * time.Hour)
+	defer daily.Stop()
+
+	prune()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-backups:
+			path, err := backupTo(ctx, repo, backup.Dir, backup.Keep, now)
+			if err != nil {
+				logs.Error("backup_failed", "dir", backup.Dir, "err", err)
+				continue
+			}
+			logs.Info("backup_complete", "path", path)
+		case <-daily.C:
+			prune()
+		}
+	}
+}
+
+// runBackup implements the `wordle backup` subcommand.
+func runBackup(ctx context.Context, repo *sqliteRepo, cfg BackupConfig, args []string) error {
+	var (
+		flags = flag.NewFlagSet("backup", flag.ExitOnError)
+		dir   = flags.String("dir", cfg.Dir, "directory to write the backup to")
+		keep  = flags.Int("keep", cfg.Keep, "how many backups to keep in the directory")
+	)
+	flags.Parse(args)
+	if *dir == "" {
+		return errors.New("usage: wordle backup -dir <dir> [-keep n], or set backup.dir in the config")
+	}
+	if *keep < 1 {
+		return errors.New("-keep must be at least 1")
+	}
+
+	path, err := backupTo(ctx, repo, *dir, *keep, time.Now())
+	if err != nil {
+		return err
+	}
+	fmt.Println(path)
+	return nil
+}
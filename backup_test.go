@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupRotation(t *testing.T) {
+	ctx := context.Background()
+	repo, err := newRepo(filepath.Join(t.TempDir(), "wordle.db"), StreakConfig{})
+	require.NoError(t, err)
+	defer repo.Close()
+	require.NoError(t, repo.SaveGame(ctx, "alice", &Game{Answer: "ultra", Started: time.Now()}))
+
+	dir := t.TempDir()
+	start := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+	var last string
+	for i := 0; i < 4; i++ {
+		last, err = backupTo(ctx, repo, dir, 2, start.Add(time.Duration(i)*time.Hour))
+		require.NoError(t, err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.Equal(t, []string{"wordle-20261001T020000Z.db", "wordle-20261001T030000Z.db"}, names)
+
+	backup, err := newRepo(last, StreakConfig{})
+	require.NoError(t, err)
+	defer backup.Close()
+	games, err := backup.ListGames(ctx, "alice")
+	require.NoError(t, err)
+	assert.Len(t, games, 1)
+}
+
+func TestPruneAbandonedGames(t *testing.T) {
+	ctx := context.Background()
+	repo, err := newRepo(filepath.Join(t.TempDir(), "wordle.db"), StreakConfig{})
+	require.NoError(t, err)
+	defer repo.Close()
+
+	old := time.Now().AddDate(0, 0, -30)
+	abandoned := &Game{Answer: "ultra", Guesses: []string{"crane"}, Started: old}
+	finished := &Game{Answer: "ultra", Guesses: []string{"ultra"}, Started: old, Won: true}
+	today := &Game{Answer: "ultra", Started: time.Now()}
+	for _, game := range []*Game{abandoned, finished, today} {
+		require.NoError(t, repo.SaveGame(ctx, "alice", game))
+	}
+	_, err = repo.DB.Exec(`UPDATE game SET created_at = datetime('now', '-30 days') WHERE id IN (?, ?)`, abandoned.ID, finished.ID)
+	require.NoError(t, err)
+
+	pruned, err := repo.PruneAbandonedGames(ctx, time.Now().AddDate(0, 0, -7), true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, pruned)
+
+	games, err := repo.ListGames(ctx, "alice")
+	require.NoError(t, err)
+	assert.Len(t, games, 2)
+
+	var archived int
+	require.NoError(t, repo.DB.QueryRow(`SELECT COUNT(*) FROM game_archive WHERE id = ?`, abandoned.ID).Scan(&archived))
+	assert.Equal(t, 1, archived)
+}
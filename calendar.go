@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// calendarShortcut is typed during a game to see which days' puzzles the
+// player won, lost or missed; `ssh host calendar` shows the same outside one.
+const calendarShortcut = "/calendar"
+
+// calendar is a player's finished games keyed by puzzle number, so each day
+// of the heatmap can be looked up directly.
+type calendar struct {
+	games map[int]Game
+	first int // the first puzzle the player finished
+	today int
+}
+
+func newCalendar(games Games, now time.Time) calendar {
+	c := calendar{games: make(map[int]Game), first: -1, today: puzzleNumber(now)}
+	for _, game := range games {
+		if !game.IsDone() {
+			continue
+		}
+		n := puzzleNumber(game.Started)
+		c.games[n] = game
+		if c.first == -1 || n < c.first {
+			c.first = n
+		}
+	}
+	return c
+}
+
+// cell draws puzzle n: the number of guesses of a win, x for a loss and a
+// dot for a day missed. Days before the player's first game, today's puzzle
+// until it is finished and days to come are blank.
+func (c calendar) cell(t *theme, n int) string {
+	game, ok := c.games[n]
+	switch {
+	case ok && game.Won && len(game.Guesses) <= 3:
+		return t.paint(t.Correct, fmt.Sprint(len(game.Guesses)))
+	case ok && game.Won:
+		return t.paint(t.Present, fmt.Sprint(len(game.Guesses)))
+	case ok:
+		return t.paint(t.Error, "x")
+	case c.first == -1 || n < c.first || n >= c.today:
+		return " "
+	}
+	return t.paint(t.KeyAbsent, "·")
+}
+
+// month counts the wins, losses and missed days of the month of day.
+func (c calendar) month(day time.Time) (won, lost, missed int) {
+	first := monthStart(day)
+	for d := first; d.Month() == first.Month(); d = d.AddDate(0, 0, 1) {
+		n := puzzleNumber(d)
+		game, ok := c.games[n]
+		switch {
+		case ok && game.Won:
+			won++
+		case ok:
+			lost++
+		case c.first != -1 && n >= c.first && n < c.today:
+			missed++
+		}
+	}
+	return won, lost, missed
+}
+
+// heatmap draws the weeks up to the end of the month of end as a grid with a
+// column per week, Monday to Sunday, like a contribution graph, labelled in
+// locale.
+func (c calendar) heatmap(t *theme, locale string, end time.Time, weeks int) []string {
+	last := monthStart(end).AddDate(0, 1, -1)
+	monday := last.AddDate(0, 0, -weekdayFromMonday(last)-7*(weeks-1))
+
+	// Month names go over the column of the week their first day falls in.
+	header := []rune(strings.Repeat(" ", 4+2*weeks))
+	for w := 0; w < weeks; w++ {
+		week := monday.AddDate(0, 0, 7*w)
+		first := monthStart(week.AddDate(0, 0, 6))
+		if first.Before(week) {
+			continue
+		}
+		name := []rune(translateNth(locale, "months_short", int(first.Month())-1))
+		if first.Month() == time.January {
+			name = []rune(fmt.Sprint(first.Year()))
+		}
+		col := 4 + 2*w
+		if col+len(name) <= len(header) && strings.TrimSpace(string(header[col-1:col+len(name)])) == "" {
+			copy(header[col:], name)
+		}
+	}
+	lines := []string{strings.TrimRight(string(header), " ")}
+
+	for d := 0; d < 7; d++ {
+		var row strings.Builder
+		switch d {
+		case 0, 2, 4:
+			row.WriteString(fmt.Sprintf("%-3s ", translateNth(locale, "weekdays_short", int(monday.AddDate(0, 0, d).Weekday()))))
+		default:
+			row.WriteString("    ")
+		}
+		for w := 0; w < weeks; w++ {
+			row.WriteString(c.cell(t, puzzleNumber(monday.AddDate(0, 0, 7*w+d))) + " ")
+		}
+		lines = append(lines, strings.TrimRight(row.String(), " "))
+	}
+
+	won, lost, missed := c.month(end)
+	return append(lines,
+		"",
+		translate(locale, "calendar_summary", monthYear(locale, end), won, lost, missed),
+		translate(locale, "calendar_legend"),
+	)
+}
+
+// describe tells a screen reader how the month of day went, in locale.
+func (c calendar) describe(locale string, day time.Time) string {
+	won, lost, missed := c.month(day)
+	sentences := []string{translate(locale, "calendar_month", monthYear(locale, day), translatePlural(locale, won, "games"), lost, missed)}
+
+	first := monthStart(day)
+	for d := first; d.Month() == first.Month(); d = d.AddDate(0, 0, 1) {
+		game, ok := c.games[puzzleNumber(d)]
+		switch {
+		case ok && game.Won:
+			sentences = append(sentences, translate(locale, "day_won", dayOfMonth(locale, d), translatePlural(locale, len(game.Guesses), "guesses")))
+		case ok:
+			sentences = append(sentences, translate(locale, "day_lost", dayOfMonth(locale, d)))
+		}
+	}
+	return strings.Join(sentences, " ")
+}
+
+// calendarWeeks returns how many weeks of the heatmap fit the terminal, or a
+// year's worth without a PTY.
+func calendarWeeks(v *view) int {
+	const year = 53
+	if v.width == 0 {
+		return year
+	}
+	weeks := (v.width - 5) / 2
+	switch {
+	case weeks > year:
+		return year
+	case weeks < 4:
+		return 4
+	}
+	return weeks
+}
+
+// showCalendar shows the heatmap of user's games in lang ending with the
+// month of day. In TUI mode the arrow keys move it by a month or a year
+// until the player presses q or enter.
+func showCalendar(ctx context.Context, v *view, repo *sqliteRepo, user, lang string, day time.Time) error {
+	games, err := repo.ListGames(ctx, user)
+	if err != nil {
+		return err
+	}
+	var (
+		now = time.Now()
+		c   = newCalendar(games.InLang(lang), now)
+	)
+
+	if v.accessible {
+		print(v, c.describe(v.locale, day)+"\n")
+		return nil
+	}
+	if !v.tui {
+		print(v, strings.Join(c.heatmap(v.theme, v.locale, day, calendarWeeks(v)), "\n")+"\n")
+		return nil
+	}
+
+	for {
+		v.draw(func() {
+			clear(v.s)
+			lines := append(c.heatmap(v.theme, v.locale, day, calendarWeeks(v)), "", v.t("calendar_help"))
+			v.write("\n")
+			v.writeBlock(lines)
+		})
+
+		key, err := readKey(v.keys)
+		if err != nil {
+			return err
+		}
+		switch key {
+		case keyCtrlC, keyCtrlD:
+			return io.EOF
+		case 'q', keyEnter:
+			return nil
+		case keyLeft, 'h':
+			day = monthStart(day).AddDate(0, -1, 0)
+		case keyRight, 'l':
+			day = monthStart(day).AddDate(0, 1, 0)
+		case keyUp, 'k':
+			day = monthStart(day).AddDate(-1, 0, 0)
+		case keyDown, 'j':
+			day = monthStart(day).AddDate(1, 0, 0)
+		}
+		if day.After(now) {
+			day = now
+		}
+	}
+}
+
+// calendarCommand shows the player's calendar, e.g. `ssh host calendar` or
+// `ssh host calendar 2026-03` for the months up to March 2026.
+func calendarCommand(ctx context.Context, v *view, repo *sqliteRepo, user string, args []string) int {
+	day := time.Now().UTC()
+	if len(args) > 0 {
+		month, err := time.Parse("2006-01", args[0])
+		if err != nil {
+			print(v, "usage: calendar [yyyy-mm]\n")
+			return 2
+		}
+		day = month
+	}
+
+	settings, err := repo.GetSettings(ctx, user)
+	if err != nil {
+		logs.Error("get_settings_failed", "player", user, "err", err)
+	}
+	if settings.Accessible {
+		v.setAccessible()
+	}
+
+	if err := showCalendar(ctx, v, repo, user, dictionaryFor(v.locale).Lang, day); err != nil && err != io.EOF {
+		logs.Error("list_games_failed", "player", user, "err", err)
+		print(v, v.t("load_games_failed")+"\n")
+		return 1
+	}
+	return 0
+}
+
+// monthYear names the month of t in locale, e.g. "October 2026".
+func monthYear(locale string, t time.Time) string {
+	return translate(locale, "month_year", translateNth(locale, "months", int(t.Month())-1), t.Year())
+}
+
+// dayOfMonth names the day of t in locale, e.g. "October 5".
+func dayOfMonth(locale string, t time.Time) string {
+	return translate(locale, "date", translateNth(locale, "months", int(t.Month())-1), t.Day())
+}
+
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// weekdayFromMonday numbers the days of the week from Monday as 0.
+func weekdayFromMonday(t time.Time) int {
+	return (int(t.Weekday()) + 6) % 7
+}
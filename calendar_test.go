@@ -0,0 +1,42 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalendarHeatmap(t *testing.T) {
+	var (
+		today = time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+		won   = Game{Guesses: []string{"crane", "slate", "water"}, Won: true, Started: time.Date(2026, time.October, 5, 9, 0, 0, 0, time.UTC)}
+		lost  = Game{Guesses: strings.Fields("a b c d e f"), Started: time.Date(2026, time.October, 6, 9, 0, 0, 0, time.UTC)}
+		plain = &theme{}
+	)
+	c := newCalendar(Games{lost, won, {Started: today}}, today)
+
+	lines := c.heatmap(plain, "en", today, 10)
+	assert.Equal(t, []string{
+		"      Sep     Oct",
+		"Mon             3 ·",
+		"                x ·",
+		"Wed             · ·",
+		"                · ·",
+		"Fri             · ·",
+		"                · ·",
+		"                · ·",
+		"",
+		"October 2026: 1 won, 1 lost, 12 missed",
+		"1-6 won in that many guesses, x lost, · missed",
+	}, lines)
+
+	assert.Equal(t, "In October 2026 you won 1 game, lost 1 and missed 12. October 5: won in 3 guesses. October 6: lost.", c.describe("en", today))
+
+	lines = c.heatmap(plain, "de", today, 10)
+	assert.Equal(t, "      Sep     Okt", lines[0])
+	assert.Equal(t, "Mo              3 ·", lines[1])
+	assert.Equal(t, "Oktober 2026: 1 gewonnen, 1 verloren, 12 verpasst", lines[9])
+	assert.Equal(t, "En octubre de 2026 ganaste 1 partida, perdiste 1 y no jugaste 12. 5 de octubre: ganada en 3 intentos. 6 de octubre: perdida.", c.describe("es", today))
+}
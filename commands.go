@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+const commandUsage = `usage: ssh <host> [command]
+
+commands:
+    play [--accessible]    play today's wordle (the default)
+    speedrun [--accessible]
+                           race today's speedrun puzzle against the clock
+    leaderboard            show the fastest speedruns
+    stats                  show your statistics in detail
+    calendar [yyyy-mm]     show the days you won, lost or missed
+    replay [puzzle] [player]
+                           watch one of your games again, the latest by
+                           default, or a finished game of a group member
+    group [join <name> | leave]
+                           show, join or leave the group you share games with
+    theme [name]           show or choose how the board is drawn
+    accessible [on|off]    show or set screen reader friendly mode
+    lang [code]            show or choose your language
+    export [-format csv]   download your games as JSON Lines or CSV
+    import                 add share grids pasted from the web game to your stats
+    admin <command>        run server administration commands, for admins
+`
+
+// runCommand handles `ssh host <command> ...` for everything but playing
+// and returns the exit status for the session.
+func runCommand(ctx context.Context, v *view, repo *sqliteRepo, admin *adminConsole, user string, args []string) int {
+	switch args[0] {
+	case "admin":
+		return admin.Run(ctx, v, user, args[1:])
+	case "stats":
+		return statsCommand(ctx, v, repo, user)
+	case "leaderboard":
+		return leaderboardCommand(ctx, v, repo)
+	case "replay":
+		return replayCommand(ctx, v, repo, user, args[1:])
+	case "group":
+		return groupCommand(ctx, v, repo, user, args[1:])
+	case "calendar":
+		return calendarCommand(ctx, v, repo, user, args[1:])
+	case "theme":
+		return themeCommand(ctx, v, repo, user, args[1:])
+	case "accessible":
+		return accessibleCommand(ctx, v, repo, user, args[1:])
+	case "lang":
+		return langCommand(ctx, v, repo, user, args[1:])
+	case "export":
+		return exportCommand(ctx, v, repo, user, args[1:])
+	case "import":
+		return importCommand(ctx, v, repo, user, args[1:])
+	case "help":
+		print(v, commandUsage)
+		return 0
+	}
+
+	print(v, v.t("unknown_command", args[0])+"\n\n"+commandUsage)
+	return 1
+}
+
+// themeCommand lists the themes, or saves the player's choice of theme.
+func themeCommand(ctx context.Context, v *view, repo *sqliteRepo, user string, args []string) int {
+	settings, err := repo.GetSettings(ctx, user)
+	if err != nil {
+		logs.Error("get_settings_failed", "player", user, "err", err)
+		print(v, v.t("load_settings_failed")+"\n")
+		return 1
+	}
+
+	if len(args) == 0 {
+		current, _ := findTheme(settings.Theme)
+		for _, t := range themes {
+			marker := " "
+			if t == current {
+				marker = "*"
+			}
+			print(v, fmt.Sprintf("%s %-14s %s  %s\n", marker, t.Name, themeSample(t), v.t("theme_"+t.Name)))
+		}
+		return 0
+	}
+
+	t, ok := findTheme(args[0])
+	if !ok {
+		print(v, v.t("unknown_theme", args[0], strings.Join(themeNames(), ", "))+"\n")
+		return 1
+	}
+
+	settings.Theme = t.Name
+	if err := repo.SaveSettings(ctx, user, settings); err != nil {
+		logs.Error("save_settings_failed", "player", user, "err", err)
+		print(v, v.t("save_settings_failed")+"\n")
+		return 1
+	}
+
+	print(v, v.t("theme_set", t.Name, themeSample(t))+"\n")
+	return 0
+}
+
+// accessibleCommand shows or saves whether the player uses accessible mode.
+func accessibleCommand(ctx context.Context, v *view, repo *sqliteRepo, user string, args []string) int {
+	settings, err := repo.GetSettings(ctx, user)
+	if err != nil {
+		logs.Error("get_settings_failed", "player", user, "err", err)
+		print(v, v.t("load_settings_failed")+"\n")
+		return 1
+	}
+
+	if len(args) == 0 {
+		print(v, v.t("accessible_mode", onOff(v, settings.Accessible))+"\n")
+		return 0
+	}
+
+	switch args[0] {
+	case "on":
+		settings.Accessible = true
+	case "off":
+		settings.Accessible = false
+	default:
+		print(v, "usage: accessible [on|off]\n")
+		return 1
+	}
+
+	if err := repo.SaveSettings(ctx, user, settings); err != nil {
+		logs.Error("save_settings_failed", "player", user, "err", err)
+		print(v, v.t("save_settings_failed")+"\n")
+		return 1
+	}
+
+	print(v, v.t("accessible_mode", onOff(v, settings.Accessible))+"\n")
+	return 0
+}
+
+// langCommand lists the languages, or saves the player's choice. Languages
+// without a word list of their own translate the text of the English game.
+func langCommand(ctx context.Context, v *view, repo *sqliteRepo, user string, args []string) int {
+	settings, err := repo.GetSettings(ctx, user)
+	if err != nil {
+		logs.Error("get_settings_failed", "player", user, "err", err)
+		print(v, v.t("load_settings_failed")+"\n")
+		return 1
+	}
+
+	if len(args) == 0 {
+		current := settings.Locale
+		if current == "" {
+			current = defaultLocale
+		}
+		for _, name := range localeNames() {
+			marker := " "
+			if name == current {
+				marker = "*"
+			}
+			words := v.t("english_words")
+			if hasDictionary(name) {
+				words = v.t("own_words")
+			}
+			print(v, fmt.Sprintf("%s %-4s %s\n", marker, name, words))
+		}
+		return 0
+	}
+
+	if _, ok := catalogs[args[0]]; !ok {
+		print(v, v.t("unknown_language", args[0], strings.Join(localeNames(), ", "))+"\n")
+		return 1
+	}
+
+	settings.Locale = args[0]
+	if err := repo.SaveSettings(ctx, user, settings); err != nil {
+		logs.Error("save_settings_failed", "player", user, "err", err)
+		print(v, v.t("save_settings_failed")+"\n")
+		return 1
+	}
+
+	print(v, translate(settings.Locale, "language_set", settings.Locale)+"\n")
+	return 0
+}
+
+type playOptions struct {
+	accessible bool
+}
+
+// parsePlayArgs parses the flags of `ssh host play`. Errors are reported to
+// the player.
+func parsePlayArgs(v *view, args []string) (playOptions, error) {
+	var opts playOptions
+	if len(args) == 0 {
+		return opts, nil
+	}
+
+	flags := flag.NewFlagSet("play", flag.ContinueOnError)
+	flags.SetOutput(viewWriter{v})
+	flags.BoolVar(&opts.accessible, "accessible", false, "describe the game in words for screen readers")
+
+	return opts, flags.Parse(args[1:])
+}
+
+// viewWriter adapts a view to io.Writer.
+type viewWriter struct{ v *view }
+
+func (w viewWriter) Write(p []byte) (int, error) {
+	w.v.write(string(p))
+	return len(p), nil
+}
+
+func onOff(v *view, b bool) string {
+	if b {
+		return v.t("on")
+	}
+	return v.t("off")
+}
+
+// themeSample shows a correct, present and absent tile in theme t.
+func themeSample(t *theme) string {
+	return t.Tile(letterCorrect, "w", false) + t.Tile(letterPresent, "o", false) + t.Tile(letterAbsent, "r", false)
+}
@@ -0,0 +1,329 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// gameModes are the game modes the server knows how to run.
+var gameModes = []string{"daily", speedrunMode}
+
+// Config is the server configuration. Values are resolved in order of
+// increasing precedence: defaults, the YAML config file, WORDLE_*
+// environment variables and finally command line flags.
+type Config struct {
+	Listen      string        `yaml:"listen"`
+	HostKeys    []string      `yaml:"host_keys"`
+	DB          string        `yaml:"db"`
+	IdleTimeout time.Duration `yaml:"idle_timeout"`
+	// ShutdownTimeout bounds how long connected players may keep playing
+	// after a shutdown signal before their sessions are closed.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	HTTP            string        `yaml:"http"`
+	Modes           []string      `yaml:"modes"`
+	RateLimits      RateLimits    `yaml:"rate_limits"`
+	Words           WordsConfig   `yaml:"words"`
+	// Languages adds a word game for each language code, e.g. "es".
+	Languages map[string]WordsConfig `yaml:"languages"`
+	Log       LogConfig              `yaml:"log"`
+	Features  Features               `yaml:"features"`
+	Backup    BackupConfig           `yaml:"backup"`
+	Retention RetentionConfig        `yaml:"retention"`
+	Streaks   StreakConfig           `yaml:"streaks"`
+	// MOTD is shown to players before the board.
+	MOTD string `yaml:"motd"`
+	// Admins are the SHA256 fingerprints of the keys allowed to run
+	// `ssh host admin ...`.
+	Admins []string `yaml:"admins"`
+}
+
+// RateLimits bounds how hard a single client can use the server. Zero means unlimited.
+type RateLimits struct {
+	ConnectionsPerMinute         int `yaml:"connections_per_minute"`
+	IdentityConnectionsPerMinute int `yaml:"identity_connections_per_minute"`
+	SessionsPerPlayer            int `yaml:"sessions_per_player"`
+	GuessesPerMinute             int `yaml:"guesses_per_minute"`
+}
+
+// WordsConfig points at word list files that replace the embedded lists;
+// see wordlist.go for their format.
+type WordsConfig struct {
+	// Dir holds a subdirectory of word lists per language.
+	Dir     string `yaml:"dir"`
+	Answers string `yaml:"answers"`
+	Allowed string `yaml:"allowed"`
+}
+
+// BackupConfig schedules online backups of the database.
+type BackupConfig struct {
+	// Dir is where backups are written; empty disables periodic backups.
+	Dir      string        `yaml:"dir"`
+	Interval time.Duration `yaml:"interval"`
+	// Keep is how many backups to keep; older ones are deleted.
+	Keep int `yaml:"keep"`
+}
+
+// RetentionConfig decides what happens to games players never finished.
+type RetentionConfig struct {
+	// AbandonedGameDays is how old an unfinished game must be before it is
+	// removed; zero keeps them forever.
+	AbandonedGameDays int `yaml:"abandoned_game_days"`
+	// Archive moves abandoned games to the game_archive table instead of
+	// deleting them.
+	Archive bool `yaml:"archive"`
+}
+
+// StreakConfig lets players earn streak freezes, each of which keeps their
+// streak alive through one missed day.
+type StreakConfig struct {
+	// FreezeEvery is how many consecutive wins earn a freeze; zero disables
+	// freezes.
+	FreezeEvery int `yaml:"freeze_every"`
+	// MaxFreezes is how many freezes a player can hold at once.
+	MaxFreezes int `yaml:"max_freezes"`
+}
+
+type LogConfig struct {
+	Format string `yaml:"format"`
+	Level  string `yaml:"level"`
+}
+
+// Features toggles optional server behaviour.
+type Features struct {
+	Audit bool `yaml:"audit"`
+}
+
+func defaultConfig() *Config {
+	return &Config{
+		Listen:          ":22",
+		HostKeys:        []string{"key.pem"},
+		DB:              "wordle.db",
+		IdleTimeout:     time.Minute * 5,
+		ShutdownTimeout: time.Second * 30,
+		Modes:           []string{"daily"},
+		Backup: BackupConfig{
+			Interval: 24 * time.Hour,
+			Keep:     7,
+		},
+		Log: LogConfig{
+			Format: "logfmt",
+			Level:  "info",
+		},
+	}
+}
+
+// loadConfig reads the YAML file at path, if any, over the defaults and then
+// applies environment overrides. Unknown keys in the file are errors, so a
+// misspelled setting can't silently keep its default. The result is not
+// otherwise validated.
+func loadConfig(path string) (*Config, error) {
+	cfg := defaultConfig()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(cfg); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	if err := cfg.applyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// applyEnv overrides config values from WORDLE_* environment variables.
+// List values are comma separated.
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+	var errs []string
+
+	str := func(name string, dst *string) {
+		if v, ok := lookup(name); ok {
+			*dst = v
+		}
+	}
+	list := func(name string, dst *[]string) {
+		if v, ok := lookup(name); ok {
+			*dst = splitList(v)
+		}
+	}
+	num := func(name string, dst *int) {
+		if v, ok := lookup(name); ok {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %q is not a number", name, v))
+				return
+			}
+			*dst = n
+		}
+	}
+	boolean := func(name string, dst *bool) {
+		if v, ok := lookup(name); ok {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %q is not a boolean", name, v))
+				return
+			}
+			*dst = b
+		}
+	}
+	duration := func(name string, dst *time.Duration) {
+		if v, ok := lookup(name); ok {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %q is not a duration", name, v))
+				return
+			}
+			*dst = d
+		}
+	}
+
+	str("WORDLE_LISTEN", &c.Listen)
+	list("WORDLE_HOST_KEYS", &c.HostKeys)
+	str("WORDLE_DB", &c.DB)
+	duration("WORDLE_IDLE_TIMEOUT", &c.IdleTimeout)
+	duration("WORDLE_SHUTDOWN_TIMEOUT", &c.ShutdownTimeout)
+	str("WORDLE_HTTP", &c.HTTP)
+	list("WORDLE_MODES", &c.Modes)
+	num("WORDLE_RATE_CONNECTIONS_PER_MINUTE", &c.RateLimits.ConnectionsPerMinute)
+	num("WORDLE_RATE_IDENTITY_CONNECTIONS_PER_MINUTE", &c.RateLimits.IdentityConnectionsPerMinute)
+	num("WORDLE_RATE_SESSIONS_PER_PLAYER", &c.RateLimits.SessionsPerPlayer)
+	num("WORDLE_RATE_GUESSES_PER_MINUTE", &c.RateLimits.GuessesPerMinute)
+	str("WORDLE_WORDS_DIR", &c.Words.Dir)
+	str("WORDLE_WORDS_ANSWERS", &c.Words.Answers)
+	str("WORDLE_WORDS_ALLOWED", &c.Words.Allowed)
+	str("WORDLE_LOG_FORMAT", &c.Log.Format)
+	str("WORDLE_LOG_LEVEL", &c.Log.Level)
+	boolean("WORDLE_FEATURE_AUDIT", &c.Features.Audit)
+	str("WORDLE_BACKUP_DIR", &c.Backup.Dir)
+	duration("WORDLE_BACKUP_INTERVAL", &c.Backup.Interval)
+	num("WORDLE_BACKUP_KEEP", &c.Backup.Keep)
+	num("WORDLE_RETENTION_ABANDONED_GAME_DAYS", &c.Retention.AbandonedGameDays)
+	boolean("WORDLE_RETENTION_ARCHIVE", &c.Retention.Archive)
+	num("WORDLE_STREAKS_FREEZE_EVERY", &c.Streaks.FreezeEvery)
+	num("WORDLE_STREAKS_MAX_FREEZES", &c.Streaks.MaxFreezes)
+	str("WORDLE_MOTD", &c.MOTD)
+	list("WORDLE_ADMINS", &c.Admins)
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Validate reports every problem with the config at once.
+func (c *Config) Validate() error {
+	var errs []string
+
+	if c.Listen == "" {
+		errs = append(errs, "listen must be set")
+	}
+	if len(c.HostKeys) == 0 {
+		errs = append(errs, "at least one host key is required")
+	}
+	if c.DB == "" {
+		errs = append(errs, "db must be set")
+	}
+	if c.IdleTimeout <= 0 {
+		errs = append(errs, "idle_timeout must be positive")
+	}
+	if c.ShutdownTimeout < 0 {
+		errs = append(errs, "shutdown_timeout must not be negative")
+	}
+
+	if len(c.Modes) == 0 {
+		errs = append(errs, "at least one mode must be enabled")
+	}
+	for _, mode := range c.Modes {
+		if !contains(gameModes, mode) {
+			errs = append(errs, fmt.Sprintf("unknown mode %q (known: %s)", mode, strings.Join(gameModes, ", ")))
+		}
+	}
+
+	rl := c.RateLimits
+	if rl.ConnectionsPerMinute < 0 || rl.IdentityConnectionsPerMinute < 0 || rl.SessionsPerPlayer < 0 || rl.GuessesPerMinute < 0 {
+		errs = append(errs, "rate_limits must not be negative")
+	}
+
+	if (c.Words.Answers == "") != (c.Words.Allowed == "") {
+		errs = append(errs, "words.answers and words.allowed must be set together")
+	}
+	for lang, words := range c.Languages {
+		if _, ok := catalogs[lang]; !ok {
+			errs = append(errs, fmt.Sprintf("unknown language %q (known: %s)", lang, strings.Join(localeNames(), ", ")))
+		}
+		if words.Dir != "" {
+			errs = append(errs, fmt.Sprintf("languages.%s cannot set dir; use words.dir", lang))
+		}
+		if words.Answers == "" || words.Allowed == "" {
+			errs = append(errs, fmt.Sprintf("languages.%s needs both answers and allowed", lang))
+		}
+	}
+
+	if c.Backup.Interval <= 0 {
+		errs = append(errs, "backup.interval must be positive")
+	}
+	if c.Backup.Keep < 1 {
+		errs = append(errs, "backup.keep must be at least 1")
+	}
+	if c.Retention.AbandonedGameDays < 0 {
+		errs = append(errs, "retention.abandoned_game_days must not be negative")
+	}
+	if c.Streaks.FreezeEvery < 0 {
+		errs = append(errs, "streaks.freeze_every must not be negative")
+	}
+	if c.Streaks.FreezeEvery > 0 && c.Streaks.MaxFreezes < 1 {
+		errs = append(errs, "streaks.max_freezes must be at least 1 when freeze_every is set")
+	}
+
+	for _, fingerprint := range c.Admins {
+		if !strings.HasPrefix(fingerprint, "SHA256:") {
+			errs = append(errs, fmt.Sprintf("admin %q must be a SHA256: key fingerprint", fingerprint))
+		}
+	}
+
+	if c.Log.Format != "logfmt" && c.Log.Format != "json" {
+		errs = append(errs, fmt.Sprintf("unknown log format %q", c.Log.Format))
+	}
+	if _, err := parseLogLevel(c.Log.Level); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
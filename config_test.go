@@ -0,0 +1,111 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wordle.yaml")
+	err := os.WriteFile(path, []byte(`
+listen: ":2222"
+idle_timeout: 90s
+rate_limits:
+  guesses_per_minute: 30
+`), 0644)
+	require.NoError(t, err)
+
+	cfg, err := loadConfig(path)
+	require.NoError(t, err)
+
+	env := mapLookup(map[string]string{
+		"WORDLE_LISTEN":    ":3333",
+		"WORDLE_HOST_KEYS": "a.pem, b.pem",
+	})
+	require.NoError(t, cfg.applyEnv(env))
+
+	assert.Equal(t, ":3333", cfg.Listen)
+	assert.Equal(t, []string{"a.pem", "b.pem"}, cfg.HostKeys)
+	assert.Equal(t, 90*time.Second, cfg.IdleTimeout)
+	assert.Equal(t, 30, cfg.RateLimits.GuessesPerMinute)
+	assert.Equal(t, "wordle.db", cfg.DB)
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestLoadConfigUnknownKey(t *testing.T) {
+	dir := t.TempDir()
+	for name, text := range map[string]string{
+		"top":    "rate_limit:\n  guesses_per_minute: 30\n",
+		"nested": "backup:\n  dirs: /var/backups\n",
+	} {
+		path := filepath.Join(dir, name+".yaml")
+		require.NoError(t, os.WriteFile(path, []byte(text), 0644))
+		_, err := loadConfig(path)
+		assert.Error(t, err, name)
+	}
+
+	// An empty file keeps the defaults, and the example is all known keys.
+	empty := filepath.Join(dir, "empty.yaml")
+	require.NoError(t, os.WriteFile(empty, nil, 0644))
+	_, err := loadConfig(empty)
+	assert.NoError(t, err)
+	_, err = loadConfig("wordle.example.yaml")
+	assert.NoError(t, err)
+}
+
+func TestConfigValidate(t *testing.T) {
+	var tests = []struct {
+		Name   string
+		Mutate func(*Config)
+		Err    string
+	}{
+		{"defaults", func(c *Config) {}, ""},
+		{"no host keys", func(c *Config) { c.HostKeys = nil }, "at least one host key"},
+		{"idle timeout", func(c *Config) { c.IdleTimeout = 0 }, "idle_timeout must be positive"},
+		{"unknown mode", func(c *Config) { c.Modes = []string{"hard"} }, `unknown mode "hard"`},
+		{"negative rate", func(c *Config) { c.RateLimits.GuessesPerMinute = -1 }, "must not be negative"},
+		{"half word lists", func(c *Config) { c.Words.Answers = "answers.txt" }, "must be set together"},
+		{"log level", func(c *Config) { c.Log.Level = "loud" }, `unknown log level "loud"`},
+		{"admin fingerprint", func(c *Config) { c.Admins = []string{"ssh-ed25519 AAAA"} }, "must be a SHA256: key fingerprint"},
+		{"streak freezes", func(c *Config) { c.Streaks.FreezeEvery = 7 }, "streaks.max_freezes must be at least 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			cfg := defaultConfig()
+			tt.Mutate(cfg)
+
+			err := cfg.Validate()
+			if tt.Err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.Err)
+		})
+	}
+}
+
+func TestConfigApplyEnvErrors(t *testing.T) {
+	env := mapLookup(map[string]string{
+		"WORDLE_IDLE_TIMEOUT":            "soon",
+		"WORDLE_RATE_GUESSES_PER_MINUTE": "many",
+	})
+
+	err := defaultConfig().applyEnv(env)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "WORDLE_IDLE_TIMEOUT")
+	assert.Contains(t, err.Error(), "WORDLE_RATE_GUESSES_PER_MINUTE")
+}
+
+func mapLookup(env map[string]string) func(string) (string, bool) {
+	return func(k string) (string, bool) {
+		v, ok := env[k]
+		return v, ok
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import "time"
+
+// Kinds of game events.
+const (
+	eventGuess      = "guess"
+	eventReject     = "reject"
+	eventHint       = "hint"
+	eventDisconnect = "disconnect"
+	eventResume     = "resume"
+)
+
+// maxEvents bounds the events kept on a game so that a client sending
+// invalid words as fast as it can doesn't grow the game without limit.
+// Accepted guesses are always kept.
+const maxEvents = 100
+
+// Event is something that happened during a game. A game's events are only
+// ever appended to, so they tell the story of the game in order: every
+// guess, rejected or not, when the player asked for a hint, and when they
+// left and came back.
+type Event struct {
+	Kind string
+	At   time.Time
+	// Word is the guess of guess and reject events.
+	Word string `json:",omitempty"`
+	// Reason says why a guess was rejected, as in rejectReason.
+	Reason string `json:",omitempty"`
+}
+
+// record appends an event that happened now to the game.
+func (g *Game) record(kind, word, reason string) {
+	if len(g.Events) >= maxEvents && kind != eventGuess {
+		return
+	}
+	g.Events = append(g.Events, Event{Kind: kind, At: time.Now(), Word: word, Reason: reason})
+}
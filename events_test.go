@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGameEvents(t *testing.T) {
+	ctx := context.Background()
+	repo, err := newRepo(filepath.Join(t.TempDir(), "wordle.db"), StreakConfig{})
+	require.NoError(t, err)
+	defer repo.Close()
+
+	game := NewGame("ultra")
+	game.Guess("cran")
+	game.Guess("xxxxx")
+	game.Guess("crane")
+	game.record(eventDisconnect, "", "")
+	require.NoError(t, repo.SaveGame(ctx, "alice", game))
+
+	games, err := repo.ListGames(ctx, "alice")
+	require.NoError(t, err)
+	require.Len(t, games, 1)
+	game = &games[0]
+	game.record(eventResume, "", "")
+	game.Guess("ultra")
+
+	kinds := make([]string, 0, len(game.Events))
+	for _, ev := range game.Events {
+		kinds = append(kinds, ev.Kind)
+	}
+	assert.Equal(t, []string{eventReject, eventReject, eventGuess, eventDisconnect, eventResume, eventGuess}, kinds)
+	assert.Equal(t, Event{Kind: eventReject, At: game.Events[1].At, Word: "xxxxx", Reason: "invalid_word"}, game.Events[1])
+	assert.Equal(t, "ultra", game.Events[5].Word)
+	assert.False(t, game.Events[5].At.Before(game.Events[0].At))
+}
+
+func TestGameEventsBounded(t *testing.T) {
+	game := NewGame("ultra")
+	for i := 0; i < maxEvents+10; i++ {
+		game.Guess("xxxxx")
+	}
+	require.Len(t, game.Events, maxEvents)
+
+	// Guesses are kept however many words were rejected.
+	game.Guess("ultra")
+	assert.Len(t, game.Events, maxEvents+1)
+	assert.Equal(t, eventGuess, game.Events[maxEvents].Kind)
+}
@@ -0,0 +1,428 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Games are exported one per line, either as JSON Lines with the player
+// alongside the game's own fields, or as CSV with a header row. Imports skip
+// games the player already has, so importing the same file twice is safe.
+const (
+	formatJSONL = "jsonl"
+	formatCSV   = "csv"
+	formatShare = "share"
+)
+
+var csvHeader = []string{"user", "lang", "answer", "guesses", "started", "finished", "won", "imported"}
+
+// writeGames writes games to w in format.
+func writeGames(w io.Writer, format string, games []PlayerGame) error {
+	switch format {
+	case formatJSONL:
+		enc := json.NewEncoder(w)
+		for _, pg := range games {
+			if err := enc.Encode(pg); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case formatCSV:
+		cw := csv.NewWriter(w)
+		cw.Write(csvHeader)
+		for _, pg := range games {
+			cw.Write([]string{
+				pg.User,
+				langOf(pg.Game),
+				pg.Answer,
+				strings.Join(pg.Guesses, " "),
+				formatTime(pg.Started),
+				formatTime(pg.Finished),
+				strconv.FormatBool(pg.Won),
+				strconv.FormatBool(pg.Imported),
+			})
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+
+	return fmt.Errorf("unknown format %q, want %s or %s", format, formatJSONL, formatCSV)
+}
+
+// readGames reads games written by writeGames.
+func readGames(r io.Reader, format string) ([]PlayerGame, error) {
+	var games []PlayerGame
+
+	switch format {
+	case formatJSONL:
+		dec := json.NewDecoder(r)
+		for {
+			var pg PlayerGame
+			err := dec.Decode(&pg)
+			if err == io.EOF {
+				return games, nil
+			}
+			if err != nil {
+				return nil, fmt.Errorf("game %d: %w", len(games)+1, err)
+			}
+			games = append(games, pg)
+		}
+
+	case formatCSV:
+		records, err := csv.NewReader(r).ReadAll()
+		if err != nil {
+			return nil, err
+		}
+		for i, record := range records {
+			if i == 0 && record[0] == csvHeader[0] {
+				continue
+			}
+			pg, err := parseCSVGame(record)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			games = append(games, pg)
+		}
+		return games, nil
+	}
+
+	return nil, fmt.Errorf("unknown format %q, want %s or %s", format, formatJSONL, formatCSV)
+}
+
+func parseCSVGame(record []string) (PlayerGame, error) {
+	var pg PlayerGame
+	if len(record) != len(csvHeader) {
+		return pg, fmt.Errorf("want %d fields, found %d", len(csvHeader), len(record))
+	}
+
+	pg.User, pg.Lang, pg.Answer = record[0], record[1], record[2]
+	pg.Guesses = strings.Fields(record[3])
+
+	var err error
+	if pg.Started, err = parseTime(record[4]); err != nil {
+		return pg, err
+	}
+	if pg.Finished, err = parseTime(record[5]); err != nil {
+		return pg, err
+	}
+	if pg.Won, err = strconv.ParseBool(record[6]); err != nil {
+		return pg, err
+	}
+	if pg.Imported, err = strconv.ParseBool(record[7]); err != nil {
+		return pg, err
+	}
+	return pg, nil
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+func langOf(game Game) string {
+	if game.Lang == "" {
+		return defaultLocale
+	}
+	return game.Lang
+}
+
+// gameKey identifies a game across servers: a player plays each language's
+// puzzle once a day.
+func gameKey(game Game) string {
+	return fmt.Sprintf("%s|%s|%s", langOf(game), game.Started.UTC().Format(dateLayout), game.Answer)
+}
+
+// importGames saves the games their players do not already have and
+// returns how many were imported and how many skipped.
+func importGames(ctx context.Context, repo *sqliteRepo, games []PlayerGame) (imported, skipped int, err error) {
+	have := make(map[string]map[string]bool)
+	for _, pg := range games {
+		if pg.User == "" {
+			return imported, skipped, errors.New("game has no player")
+		}
+		if err := checkGame(pg.Game); err != nil {
+			return imported, skipped, fmt.Errorf("invalid game for %s on %s: %w", pg.User, pg.Started.Format(dateLayout), err)
+		}
+
+		keys, ok := have[pg.User]
+		if !ok {
+			existing, err := repo.ListGames(ctx, pg.User)
+			if err != nil {
+				return imported, skipped, err
+			}
+			keys = make(map[string]bool, len(existing))
+			for _, game := range existing {
+				keys[gameKey(game)] = true
+			}
+			have[pg.User] = keys
+		}
+
+		key := gameKey(pg.Game)
+		if keys[key] {
+			skipped++
+			continue
+		}
+
+		game := pg.Game
+		game.ID = 0
+		if err := repo.SaveGame(ctx, pg.User, &game); err != nil {
+			return imported, skipped, err
+		}
+		keys[key] = true
+		imported++
+	}
+	return imported, skipped, nil
+}
+
+// checkGame returns why game could not have been played, if it couldn't.
+func checkGame(game Game) error {
+	if !isWord(game.Answer) {
+		return fmt.Errorf("answer %q is not a %d letter word", game.Answer, WordLength)
+	}
+	if len(game.Guesses) > MaxGuesses {
+		return fmt.Errorf("%d guesses, want at most %d", len(game.Guesses), MaxGuesses)
+	}
+	for _, guess := range game.Guesses {
+		if guess == unknownGuess && game.Imported {
+			continue
+		}
+		if !isWord(guess) {
+			return fmt.Errorf("guess %q is not a %d letter word", guess, WordLength)
+		}
+	}
+	if game.Won && (len(game.Guesses) == 0 || game.Guesses[len(game.Guesses)-1] != game.Answer) {
+		return errors.New("won without guessing the answer last")
+	}
+	return nil
+}
+
+func isWord(s string) bool {
+	if utf8.RuneCountInString(s) != WordLength {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// runExport implements the `wordle export` subcommand.
+func runExport(ctx context.Context, repo *sqliteRepo, args []string) error {
+	var (
+		flags  = flag.NewFlagSet("export", flag.ExitOnError)
+		user   = flags.String("user", "", "only export this player's games")
+		format = flags.String("format", formatJSONL, "jsonl or csv")
+		out    = flags.String("o", "", "file to write to instead of stdout")
+	)
+	flags.Parse(args)
+
+	games, err := repo.ListPlayerGames(ctx, *user)
+	if err != nil {
+		return err
+	}
+
+	w := io.Writer(os.Stdout)
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := writeGames(w, *format, games); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "exported %s\n", plural(len(games), "game", "games"))
+	return nil
+}
+
+// runImport implements the `wordle import` subcommand.
+func runImport(ctx context.Context, repo *sqliteRepo, args []string) error {
+	var (
+		flags  = flag.NewFlagSet("import", flag.ExitOnError)
+		user   = flags.String("user", "", "import the games as this player; required for share grids")
+		format = flags.String("format", formatJSONL, "jsonl, csv or share")
+		lang   = flags.String("lang", defaultLocale, "language of share grids")
+	)
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		return errors.New("usage: wordle import [-format jsonl|csv|share] [-user player] [-lang code] <file|->")
+	}
+
+	in := io.Reader(os.Stdin)
+	if path := flags.Arg(0); path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	var (
+		games []PlayerGame
+		err   error
+	)
+	if *format == formatShare {
+		if *user == "" {
+			return errors.New("share grids need -user")
+		}
+		games, err = readShareGames(ctx, repo, in, *user, dictionaryFor(*lang))
+	} else {
+		games, err = readGames(in, *format)
+	}
+	if err != nil {
+		return err
+	}
+	if *user != "" {
+		for i := range games {
+			games[i].User = *user
+		}
+	}
+
+	imported, skipped, err := importGames(ctx, repo, games)
+	fmt.Printf("imported %s, skipped %d already present\n", plural(imported, "game", "games"), skipped)
+	if err != nil {
+		return err
+	}
+	repo.Audit(ctx, auditEvent{Event: auditAdmin, User: "cli", Detail: fmt.Sprintf("import %d games", imported)})
+	return nil
+}
+
+// finishedGames returns the games that are over, won or lost.
+func finishedGames(games []PlayerGame) []PlayerGame {
+	finished := make([]PlayerGame, 0, len(games))
+	for _, pg := range games {
+		if pg.IsDone() {
+			finished = append(finished, pg)
+		}
+	}
+	return finished
+}
+
+// readShareGames reads share grids pasted from the web game as user's games,
+// with the answers that were played on their days.
+func readShareGames(ctx context.Context, repo *sqliteRepo, r io.Reader, user string, dict *dictionary) ([]PlayerGame, error) {
+	text, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	grids, err := parseShareGrids(string(text))
+	if err != nil {
+		return nil, err
+	}
+
+	// Today's puzzle can't be imported either: it would be a win without
+	// playing, and the board would show the answer.
+	today := puzzleNumber(time.Now())
+	games := make([]PlayerGame, 0, len(grids))
+	for _, grid := range grids {
+		switch {
+		case grid.Puzzle == today:
+			return nil, fmt.Errorf("Wordle %d is today's puzzle, play it here instead", grid.Puzzle)
+		case grid.Puzzle > today:
+			return nil, fmt.Errorf("Wordle %d has not been played yet", grid.Puzzle)
+		case puzzleDate(grid.Puzzle).Before(dict.FirstDay()):
+			return nil, fmt.Errorf("Wordle %d is from before the %s word list starts", grid.Puzzle, dict.Lang)
+		}
+		answer := todaysAnswer(ctx, repo, dict, puzzleDate(grid.Puzzle).Add(12*time.Hour))
+		games = append(games, PlayerGame{User: user, Game: grid.Game(dict.Lang, answer)})
+	}
+	return games, nil
+}
+
+// exportCommand writes the player's own finished games to the session, e.g.
+// `ssh host export -format csv > games.csv`. Games still being played are
+// left out, since they carry the answer.
+func exportCommand(ctx context.Context, v *view, repo *sqliteRepo, user string, args []string) int {
+	var (
+		flags  = flag.NewFlagSet("export", flag.ContinueOnError)
+		format = flags.String("format", formatJSONL, "jsonl or csv")
+	)
+	flags.SetOutput(viewWriter{v})
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	games, err := repo.ListPlayerGames(ctx, user)
+	if err != nil {
+		logs.Error("list_games_failed", "player", user, "err", err)
+		print(v, "failed to load your games\n")
+		return 1
+	}
+	if err := writeGames(viewWriter{v}, *format, finishedGames(games)); err != nil {
+		print(v, err.Error()+"\n")
+		return 1
+	}
+	return 0
+}
+
+// importCommand adds share grids pasted from the web game to the player's
+// statistics, e.g. `ssh host import < grids.txt`.
+func importCommand(ctx context.Context, v *view, repo *sqliteRepo, user string, args []string) int {
+	var (
+		flags = flag.NewFlagSet("import", flag.ContinueOnError)
+		lang  = flags.String("lang", v.locale, "language of the games")
+	)
+	flags.SetOutput(viewWriter{v})
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	var in io.Reader = v.s
+	if v.pty {
+		// Pasted into a terminal: collect lines until Ctrl-D.
+		print(v, "Paste your share grids, then press Ctrl-D on an empty line.\n")
+		var b strings.Builder
+		for {
+			line, err := v.term.ReadLine()
+			if err != nil {
+				break
+			}
+			b.WriteString(line + "\n")
+		}
+		in = strings.NewReader(b.String())
+	}
+
+	games, err := readShareGames(ctx, repo, in, user, dictionaryFor(*lang))
+	if err != nil {
+		print(v, err.Error()+"\n")
+		return 1
+	}
+	imported, skipped, err := importGames(ctx, repo, games)
+	if err != nil {
+		logs.Error("import_games_failed", "player", user, "err", err)
+		print(v, "failed to import your games\n")
+		return 1
+	}
+	logs.Info("games_imported", "player", user, "imported", imported, "skipped", skipped)
+
+	print(v, fmt.Sprintf("imported %s, skipped %d already present\n", plural(imported, "game", "games"), skipped))
+	return 0
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPuzzleNumber(t *testing.T) {
+	ultra := time.Date(2022, time.February, 12, 18, 0, 0, 0, time.UTC)
+	assert.Equal(t, 238, puzzleNumber(ultra))
+	assert.Equal(t, "2022-02-12", puzzleDate(238).Format(dateLayout))
+	assert.Equal(t, "ultra", dictionaryFor(defaultLocale).WordOfTheDay(ultra))
+}
+
+func TestParseShareGrids(t *testing.T) {
+	text := `Wordle 238 3/6*
+
+⬛🟨⬛⬛⬛
+⬜🟩🟩🟨⬛
+🟩🟩🟩🟩🟩
+
+Wordle 1,239 X/6
+
+⬛⬛⬛⬛⬛
+⬛⬛⬛⬛⬛
+🟦⬛⬛⬛⬛
+🟧⬛⬛⬛⬛
+🟧🟧⬛⬛⬛
+🟧🟧🟧⬛⬛
+`
+	grids, err := parseShareGrids(text)
+	require.NoError(t, err)
+	assert.Equal(t, []shareGrid{{Puzzle: 238, Guesses: 3, Won: true}, {Puzzle: 1239, Guesses: 6}}, grids)
+
+	game := grids[0].Game(defaultLocale, "ultra")
+	assert.Equal(t, "ultra", game.Answer)
+	assert.Equal(t, []string{unknownGuess, unknownGuess, "ultra"}, game.Guesses)
+	assert.True(t, game.Won)
+
+	_, err = parseShareGrids("Wordle 238 4/6\n\n🟩🟩🟩🟩🟩\n")
+	assert.Error(t, err)
+}
+
+func TestReadShareGames(t *testing.T) {
+	repo, err := newRepo(filepath.Join(t.TempDir(), "wordle.db"), StreakConfig{})
+	require.NoError(t, err)
+	defer repo.Close()
+
+	var (
+		ctx  = context.Background()
+		dict = dictionaryFor(defaultLocale)
+		grid = func(puzzle int) io.Reader {
+			return strings.NewReader(fmt.Sprintf("Wordle %d 1/6\n\n🟩🟩🟩🟩🟩\n", puzzle))
+		}
+	)
+
+	games, err := readShareGames(ctx, repo, grid(238), "alice", dict)
+	require.NoError(t, err)
+	require.Len(t, games, 1)
+	assert.Equal(t, "ultra", games[0].Answer)
+	assert.Equal(t, []string{"ultra"}, games[0].Guesses)
+
+	// Days an admin scheduled had the scheduled answer.
+	require.NoError(t, repo.ScheduleAnswer(ctx, ScheduledAnswer{Date: "2022-02-12", Lang: defaultLocale, Word: "adieu", CreatedBy: "admin"}))
+	games, err = readShareGames(ctx, repo, grid(238), "alice", dict)
+	require.NoError(t, err)
+	assert.Equal(t, "adieu", games[0].Answer)
+	assert.Equal(t, []string{"adieu"}, games[0].Guesses)
+
+	today := puzzleNumber(time.Now())
+	_, err = readShareGames(ctx, repo, grid(today), "alice", dict)
+	assert.Error(t, err)
+	_, err = readShareGames(ctx, repo, grid(today+1), "alice", dict)
+	assert.Error(t, err)
+}
+
+func TestExportImport(t *testing.T) {
+	repo, err := newRepo(filepath.Join(t.TempDir(), "wordle.db"), StreakConfig{})
+	require.NoError(t, err)
+	defer repo.Close()
+
+	var (
+		ctx     = context.Background()
+		started = time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+		games   = []PlayerGame{
+			{User: "alice|10.0.0.1", Game: Game{Answer: "crane", Guesses: []string{"slate", "crane"}, Started: started, Finished: started.Add(time.Minute), Won: true, Lang: "en"}},
+			{User: "bob|10.0.0.2", Game: Game{Answer: "crane", Guesses: []string{"slate"}, Started: started, Lang: "en"}},
+		}
+	)
+
+	for _, format := range []string{formatJSONL, formatCSV} {
+		var buf bytes.Buffer
+		require.NoError(t, writeGames(&buf, format, games))
+		read, err := readGames(strings.NewReader(buf.String()), format)
+		require.NoError(t, err, format)
+		assert.Equal(t, games, read, format)
+	}
+
+	imported, skipped, err := importGames(ctx, repo, games)
+	require.NoError(t, err)
+	assert.Equal(t, 2, imported)
+	assert.Equal(t, 0, skipped)
+
+	// Importing again changes nothing.
+	imported, skipped, err = importGames(ctx, repo, games)
+	require.NoError(t, err)
+	assert.Equal(t, 0, imported)
+	assert.Equal(t, 2, skipped)
+
+	all, err := repo.ListPlayerGames(ctx, "")
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+
+	// Players exporting their own games don't get the answer of one
+	// they're still playing.
+	finished := finishedGames(all)
+	require.Len(t, finished, 1)
+	assert.Equal(t, "alice|10.0.0.1", finished[0].User)
+}
+
+func TestImportInvalidGames(t *testing.T) {
+	repo, err := newRepo(filepath.Join(t.TempDir(), "wordle.db"), StreakConfig{})
+	require.NoError(t, err)
+	defer repo.Close()
+
+	var tests = []struct {
+		Name string
+		CSV  string
+	}{
+		{"won-without-guesses", "alice,en,crane,,2026-10-01T09:00:00Z,2026-10-01T09:01:00Z,true,false"},
+		{"won-wrong-guess", "alice,en,crane,slate,2026-10-01T09:00:00Z,2026-10-01T09:01:00Z,true,false"},
+		{"short-guess", "alice,en,crane,sla crane,2026-10-01T09:00:00Z,2026-10-01T09:01:00Z,true,false"},
+		{"unknown-guess", "alice,en,crane,????? crane,2026-10-01T09:00:00Z,2026-10-01T09:01:00Z,true,false"},
+		{"no-answer", "alice,en,,slate,2026-10-01T09:00:00Z,,false,false"},
+		{"too-many-guesses", "alice,en,crane,slate slate slate slate slate slate slate,2026-10-01T09:00:00Z,,false,false"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			games, err := readGames(strings.NewReader(strings.Join(csvHeader, ",")+"\n"+tt.CSV+"\n"), formatCSV)
+			require.NoError(t, err)
+			_, _, err = importGames(context.Background(), repo, games)
+			assert.Error(t, err)
+		})
+	}
+
+	// Games imported from share grids keep their unknown guesses.
+	games, err := readGames(strings.NewReader(strings.Join(csvHeader, ",")+"\n"+
+		"alice,en,crane,????? crane,2026-10-01T00:00:00Z,2026-10-01T00:00:00Z,true,true\n"), formatCSV)
+	require.NoError(t, err)
+	imported, _, err := importGames(context.Background(), repo, games)
+	require.NoError(t, err)
+	assert.Equal(t, 1, imported)
+}
@@ -5,6 +5,9 @@ go 1.16
 require (
 	github.com/gliderlabs/ssh v0.3.3
 	github.com/mattn/go-sqlite3 v1.14.11
+	github.com/prometheus/client_golang v1.11.1
 	github.com/stretchr/testify v1.7.0
 	golang.org/x/crypto v0.0.0-20210616213533-5ff15b29337e
+	golang.org/x/time v0.0.0-20210723032227-1f47c861a9ac
+	gopkg.in/yaml.v3 v3.0.1
 )
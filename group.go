@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Groups are joined by name, e.g. `ssh host group join book-club`, so
+// friends share one by telling each other its name. Players in a group can
+// watch each other's finished games with `replay`.
+var groupName = regexp.MustCompile(`^[a-z0-9-]{2,32}$`)
+
+// GroupOf returns the group user is in, or "" if none.
+func (r *sqliteRepo) GroupOf(ctx context.Context, user string) (string, error) {
+	defer observeRepo("group_of", time.Now())
+
+	var name string
+	err := r.DB.QueryRowContext(ctx, `SELECT name FROM player_group WHERE user=?`, user).Scan(&name)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return name, err
+}
+
+// JoinGroup puts user in the group name, leaving any other.
+func (r *sqliteRepo) JoinGroup(ctx context.Context, user, name string) error {
+	defer observeRepo("join_group", time.Now())
+
+	const upsert = `INSERT INTO player_group(user, name) VALUES(?, ?)
+		ON CONFLICT(user) DO UPDATE SET name=excluded.name, joined_at=CURRENT_TIMESTAMP`
+	_, err := r.DB.ExecContext(ctx, upsert, user, name)
+	return err
+}
+
+// LeaveGroup takes user out of their group.
+func (r *sqliteRepo) LeaveGroup(ctx context.Context, user string) error {
+	defer observeRepo("leave_group", time.Now())
+
+	_, err := r.DB.ExecContext(ctx, `DELETE FROM player_group WHERE user=?`, user)
+	return err
+}
+
+// GroupMembers returns the players in the group name, in the order they
+// joined.
+func (r *sqliteRepo) GroupMembers(ctx context.Context, name string) ([]string, error) {
+	defer observeRepo("group_members", time.Now())
+
+	rows, err := r.DB.QueryContext(ctx, `SELECT user FROM player_group WHERE name=? ORDER BY joined_at, user`, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []string
+	for rows.Next() {
+		var user string
+		if err := rows.Scan(&user); err != nil {
+			return nil, err
+		}
+		members = append(members, user)
+	}
+	return members, rows.Err()
+}
+
+// groupMember returns the player key of the member of user's group called
+// name, as shown by playerName. Its errors are for the player.
+func groupMember(ctx context.Context, v *view, repo *sqliteRepo, user, name string) (string, error) {
+	group, err := repo.GroupOf(ctx, user)
+	if err != nil {
+		logs.Error("group_of_failed", "player", user, "err", err)
+		return "", errors.New(v.t("load_group_failed"))
+	}
+	if group == "" {
+		return "", errors.New(v.t("group_none"))
+	}
+	members, err := repo.GroupMembers(ctx, group)
+	if err != nil {
+		logs.Error("group_members_failed", "group", group, "err", err)
+		return "", errors.New(v.t("load_group_failed"))
+	}
+
+	var matched []string
+	for _, member := range members {
+		if member == name {
+			return member, nil
+		}
+		if strings.EqualFold(playerName(member), name) {
+			matched = append(matched, member)
+		}
+	}
+	switch len(matched) {
+	case 0:
+		return "", errors.New(v.t("no_such_member", name))
+	case 1:
+		return matched[0], nil
+	}
+	return "", errors.New(v.t("ambiguous_member", name))
+}
+
+// groupCommand shows the player's group or changes it, e.g.
+// `ssh host group join book-club` or `ssh host group leave`.
+func groupCommand(ctx context.Context, v *view, repo *sqliteRepo, user string, args []string) int {
+	group, err := repo.GroupOf(ctx, user)
+	if err != nil {
+		logs.Error("group_of_failed", "player", user, "err", err)
+		print(v, v.t("load_group_failed")+"\n")
+		return 1
+	}
+
+	switch {
+	case len(args) == 0:
+		if group == "" {
+			print(v, v.t("group_none")+"\n")
+			return 0
+		}
+		members, err := repo.GroupMembers(ctx, group)
+		if err != nil {
+			logs.Error("group_members_failed", "group", group, "err", err)
+			print(v, v.t("load_group_failed")+"\n")
+			return 1
+		}
+		names := make([]string, 0, len(members))
+		for _, member := range members {
+			names = append(names, playerName(member))
+		}
+		sort.Strings(names)
+		print(v, v.t("group_members", group, strings.Join(names, ", "))+"\n")
+		return 0
+
+	case len(args) == 2 && args[0] == "join":
+		name := strings.ToLower(args[1])
+		if !groupName.MatchString(name) {
+			print(v, v.t("group_invalid")+"\n")
+			return 1
+		}
+		if err := repo.JoinGroup(ctx, user, name); err != nil {
+			logs.Error("join_group_failed", "player", user, "err", err)
+			print(v, v.t("save_group_failed")+"\n")
+			return 1
+		}
+		logs.Info("group_joined", "player", user, "group", name)
+		print(v, v.t("group_joined", name)+"\n")
+		return 0
+
+	case len(args) == 1 && args[0] == "leave":
+		if group == "" {
+			print(v, v.t("group_none")+"\n")
+			return 0
+		}
+		if err := repo.LeaveGroup(ctx, user); err != nil {
+			logs.Error("leave_group_failed", "player", user, "err", err)
+			print(v, v.t("save_group_failed")+"\n")
+			return 1
+		}
+		print(v, v.t("group_left", group)+"\n")
+		return 0
+	}
+
+	print(v, "usage: group [join <name> | leave]\n")
+	return 2
+}
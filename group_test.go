@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroups(t *testing.T) {
+	ctx := context.Background()
+	repo, err := newRepo(filepath.Join(t.TempDir(), "wordle.db"), StreakConfig{})
+	require.NoError(t, err)
+	defer repo.Close()
+
+	require.NoError(t, repo.JoinGroup(ctx, "alice|10.0.0.1", "club"))
+	require.NoError(t, repo.JoinGroup(ctx, "bob|10.0.0.2", "club"))
+	require.NoError(t, repo.JoinGroup(ctx, "carol|10.0.0.3", "other"))
+	require.NoError(t, repo.JoinGroup(ctx, "carol|10.0.0.3", "club"))
+
+	members, err := repo.GroupMembers(ctx, "club")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"alice|10.0.0.1", "bob|10.0.0.2", "carol|10.0.0.3"}, members)
+
+	require.NoError(t, repo.LeaveGroup(ctx, "carol|10.0.0.3"))
+	group, err := repo.GroupOf(ctx, "carol|10.0.0.3")
+	require.NoError(t, err)
+	assert.Equal(t, "", group)
+
+	// Merging a player moves their group along with their games.
+	_, err = repo.MergePlayers(ctx, "bob|10.0.0.2", "bob|10.0.0.9")
+	require.NoError(t, err)
+	group, err = repo.GroupOf(ctx, "bob|10.0.0.9")
+	require.NoError(t, err)
+	assert.Equal(t, "club", group)
+
+	v := &view{locale: defaultLocale}
+	member, err := groupMember(ctx, v, repo, "alice|10.0.0.1", "Bob")
+	require.NoError(t, err)
+	assert.Equal(t, "bob|10.0.0.9", member)
+	_, err = groupMember(ctx, v, repo, "alice|10.0.0.1", "carol")
+	assert.EqualError(t, err, "nobody called carol is in your group")
+	_, err = groupMember(ctx, v, repo, "carol|10.0.0.3", "alice")
+	assert.Error(t, err)
+}
+
+func TestReplayGroupMember(t *testing.T) {
+	ctx := context.Background()
+	repo, err := newRepo(filepath.Join(t.TempDir(), "wordle.db"), StreakConfig{})
+	require.NoError(t, err)
+	defer repo.Close()
+
+	play := func(user string, started time.Time, guesses ...string) {
+		game := NewGame("ultra")
+		game.Started = started
+		for _, guess := range guesses {
+			game.Guess(guess)
+		}
+		require.NoError(t, repo.SaveGame(ctx, user, game))
+	}
+	var (
+		v         = &view{locale: defaultLocale}
+		now       = time.Now()
+		yesterday = now.AddDate(0, 0, -1)
+	)
+	play("bob", yesterday, "crane", "ultra")
+	play("bob", now, "ultra")
+	play("carol", yesterday, "ultra")
+	require.NoError(t, repo.JoinGroup(ctx, "alice", "club"))
+	require.NoError(t, repo.JoinGroup(ctx, "bob", "club"))
+
+	game, err := findReplay(ctx, v, repo, "alice", "bob", puzzleNumber(yesterday))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"crane", "ultra"}, game.Guesses)
+
+	// Only players in the same group can be watched.
+	_, err = findReplay(ctx, v, repo, "alice", "carol", puzzleNumber(yesterday))
+	assert.Error(t, err)
+
+	// Today's games of others give the answer away until alice has
+	// finished hers.
+	_, err = findReplay(ctx, v, repo, "alice", "bob", -1)
+	assert.EqualError(t, err, "finish today's puzzle before watching anyone else's")
+	play("alice", now, "crane")
+	_, err = findReplay(ctx, v, repo, "alice", "bob", -1)
+	assert.Error(t, err)
+	play("alice", now, "crane", "crane", "crane", "crane", "crane", "crane")
+	game, err = findReplay(ctx, v, repo, "alice", "bob", -1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ultra"}, game.Guesses)
+}
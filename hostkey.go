@@ -0,0 +1,156 @@
+package main
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// loadHostKeys parses each PEM encoded host key in paths. A key file that
+// does not exist yet is generated as ed25519 and written to disk, so a fresh
+// checkout can run the server without any setup.
+func loadHostKeys(paths []string) ([]gossh.Signer, error) {
+	signers := make([]gossh.Signer, 0, len(paths))
+	for _, path := range paths {
+		keyPEM, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			keyPEM, err = generateHostKeyFile(path, "ed25519", 0)
+			if err != nil {
+				return nil, err
+			}
+			logs.Info("host_key_generated", "path", path, "type", "ed25519")
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		signer, err := gossh.ParsePrivateKey(keyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		signers = append(signers, signer)
+	}
+
+	return signers, nil
+}
+
+// generateHostKeyFile generates a key of the given type and writes it to path
+// with owner-only permissions. It refuses to overwrite an existing file.
+func generateHostKeyFile(path, keyType string, bits int) ([]byte, error) {
+	key, err := generateHostKey(keyType, bits)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := marshalHostKey(key)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM := pem.EncodeToMemory(block)
+
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return nil, err
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Write(keyPEM); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+
+	return keyPEM, nil
+}
+
+func generateHostKey(keyType string, bits int) (crypto.Signer, error) {
+	switch keyType {
+	case "ed25519":
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+	case "ecdsa":
+		var curve elliptic.Curve
+		switch bits {
+		case 0, 256:
+			curve = elliptic.P256()
+		case 384:
+			curve = elliptic.P384()
+		case 521:
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("ecdsa keys must be 256, 384 or 521 bits")
+		}
+		return ecdsa.GenerateKey(curve, rand.Reader)
+	case "rsa":
+		if bits == 0 {
+			bits = 3072
+		}
+		if bits < 2048 {
+			return nil, fmt.Errorf("rsa keys must be at least 2048 bits")
+		}
+		return rsa.GenerateKey(rand.Reader, bits)
+	}
+
+	return nil, fmt.Errorf("unknown key type %q (want ed25519, ecdsa or rsa)", keyType)
+}
+
+func marshalHostKey(key crypto.Signer) (*pem.Block, error) {
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		return &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}, nil
+	case *ecdsa.PrivateKey:
+		der, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			return nil, err
+		}
+		return &pem.Block{Type: "EC PRIVATE KEY", Bytes: der}, nil
+	default:
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			return nil, err
+		}
+		return &pem.Block{Type: "PRIVATE KEY", Bytes: der}, nil
+	}
+}
+
+// runKeygen implements the `wordle keygen` subcommand.
+func runKeygen(args []string) error {
+	var (
+		flags   = flag.NewFlagSet("keygen", flag.ExitOnError)
+		keyType = flags.String("type", "ed25519", "key type: ed25519, ecdsa or rsa")
+		bits    = flags.Int("bits", 0, "key size for ecdsa (256, 384, 521) or rsa (default 3072)")
+		out     = flags.String("out", "key.pem", "file to write the private key to")
+	)
+	flags.Parse(args)
+
+	keyPEM, err := generateHostKeyFile(*out, *keyType, *bits)
+	if err != nil {
+		return err
+	}
+	signer, err := gossh.ParsePrivateKey(keyPEM)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("wrote %s key to %s\n%s\n", *keyType, *out, gossh.FingerprintSHA256(signer.PublicKey()))
+	return nil
+}
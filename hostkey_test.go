@@ -0,0 +1,107 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+func TestGenerateHostKey(t *testing.T) {
+	var tests = []struct {
+		Name    string
+		Type    string
+		Bits    int
+		SSHType string
+		Size    int
+		PEMType string
+	}{
+		{"ed25519", "ed25519", 0, gossh.KeyAlgoED25519, 0, "PRIVATE KEY"},
+		{"ecdsa-default", "ecdsa", 0, gossh.KeyAlgoECDSA256, 256, "EC PRIVATE KEY"},
+		{"ecdsa-256", "ecdsa", 256, gossh.KeyAlgoECDSA256, 256, "EC PRIVATE KEY"},
+		{"ecdsa-384", "ecdsa", 384, gossh.KeyAlgoECDSA384, 384, "EC PRIVATE KEY"},
+		{"ecdsa-521", "ecdsa", 521, gossh.KeyAlgoECDSA521, 521, "EC PRIVATE KEY"},
+		{"rsa-default", "rsa", 0, gossh.KeyAlgoRSA, 3072, "RSA PRIVATE KEY"},
+		{"rsa-2048", "rsa", 2048, gossh.KeyAlgoRSA, 2048, "RSA PRIVATE KEY"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			key, err := generateHostKey(tt.Type, tt.Bits)
+			require.NoError(t, err)
+			switch key := key.(type) {
+			case *ecdsa.PrivateKey:
+				assert.Equal(t, tt.Size, key.Curve.Params().BitSize)
+			case *rsa.PrivateKey:
+				assert.Equal(t, tt.Size, key.N.BitLen())
+			}
+
+			block, err := marshalHostKey(key)
+			require.NoError(t, err)
+			assert.Equal(t, tt.PEMType, block.Type)
+			signer, err := gossh.ParsePrivateKey(pem.EncodeToMemory(block))
+			require.NoError(t, err)
+			assert.Equal(t, tt.SSHType, signer.PublicKey().Type())
+		})
+	}
+}
+
+func TestGenerateHostKeyInvalid(t *testing.T) {
+	var tests = []struct {
+		Name string
+		Type string
+		Bits int
+	}{
+		{"dsa", "dsa", 0},
+		{"no-type", "", 0},
+		{"ecdsa-128", "ecdsa", 128},
+		{"ecdsa-2048", "ecdsa", 2048},
+		{"rsa-1024", "rsa", 1024},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			_, err := generateHostKey(tt.Type, tt.Bits)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestHostKeyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys", "host.pem")
+
+	// A missing key is generated as ed25519, stored as PKCS8.
+	signers, err := loadHostKeys([]string{path})
+	require.NoError(t, err)
+	require.Len(t, signers, 1)
+	assert.Equal(t, gossh.KeyAlgoED25519, signers[0].PublicKey().Type())
+
+	keyPEM, err := os.ReadFile(path)
+	require.NoError(t, err)
+	block, _ := pem.Decode(keyPEM)
+	require.NotNil(t, block)
+	assert.Equal(t, "PRIVATE KEY", block.Type)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// Loading it again gives the same key, and keygen won't overwrite it.
+	again, err := loadHostKeys([]string{path})
+	require.NoError(t, err)
+	assert.Equal(t, signers[0].PublicKey().Marshal(), again[0].PublicKey().Marshal())
+	_, err = generateHostKeyFile(path, "ed25519", 0)
+	assert.Error(t, err)
+
+	raw, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	require.NoError(t, err)
+	_, ok := raw.(ed25519.PrivateKey)
+	assert.True(t, ok, "%T", raw)
+}
@@ -0,0 +1,673 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const defaultLocale = "en"
+
+// catalog maps message keys to format strings for one locale.
+type catalog map[string]string
+
+// catalogs holds the UI text for every supported locale. Messages missing
+// from a locale fall back to English.
+var catalogs = map[string]catalog{
+	"en": {
+		"title":                      "Wordle",
+		"winner":                     "Winner!",
+		"statistics":                 "Statistics",
+		"played":                     "played",
+		"win_percent":                "win %",
+		"current_streak":             "current streak",
+		"max_streak":                 "max streak",
+		"streak_freezes":             "streak freezes",
+		"guess_distribution":         "guess distribution",
+		"today":                      "today",
+		"today_summary":              "today: %d played, %d%% won, %.1f avg",
+		"today_short":                "today: %d%% of %d won",
+		"percentile":                 "better than %d%% of players",
+		"percentile_short":           "beat %d%% of players",
+		"first_today":                "first to finish today",
+		"next_wordle":                "Next Wordle in %d hours %d mins",
+		"next_wordle_short":          "next %dh %dm",
+		"word_length":                "word must be %d letters",
+		"invalid_word":               "invalid word %q",
+		"slow_down":                  "slow down",
+		"press_key":                  "Press any key to play",
+		"press_enter":                "Press enter to play",
+		"speedrun":                   "Speedrun",
+		"speedrun_start":             "the clock starts when you type",
+		"clock_started":              "the clock is running",
+		"time":                       "time",
+		"splits":                     "splits",
+		"not_solved":                 "not solved, no time",
+		"fastest_today":              "fastest today",
+		"replay_hint":                "/replay to watch again, any key to quit",
+		"replay_hint_short":          "/replay or any key",
+		"hint_used":                  "asked for a hint",
+		"left_game":                  "left the game",
+		"came_back":                  "came back",
+		"load_stats_failed":          "failed to load your statistics",
+		"load_games_failed":          "failed to load your games",
+		"no_games_yet":               "you have not finished a game yet",
+		"average_guesses":            "average guesses",
+		"median_solve_time":          "median solve time",
+		"favourite_opener":           "favourite opener",
+		"best_word":                  "best word",
+		"worst_word":                 "worst word",
+		"word_in":                    "%s in %s",
+		"word_lost":                  "%s lost",
+		"by_weekday":                 "by weekday",
+		"by_opener":                  "by opener",
+		"by_first_letter":            "by first letter",
+		"won_of":                     "%3d%% won of %s",
+		"average":                    "%.1f guesses",
+		"games_one":                  "%d game",
+		"games_many":                 "%d games",
+		"guesses_one":                "%d guess",
+		"guesses_many":               "%d guesses",
+		"wins_one":                   "%d win",
+		"wins_many":                  "%d wins",
+		"weekdays":                   "Sunday Monday Tuesday Wednesday Thursday Friday Saturday",
+		"months":                     "January February March April May June July August September October November December",
+		"months_short":               "Jan Feb Mar Apr May Jun Jul Aug Sep Oct Nov Dec",
+		"weekdays_short":             "Sun Mon Tue Wed Thu Fri Sat",
+		"month_year":                 "%s %d",
+		"date":                       "%[1]s %[2]d",
+		"calendar_summary":           "%s: %d won, %d lost, %d missed",
+		"calendar_legend":            "1-6 won in that many guesses, x lost, · missed",
+		"calendar_help":              "←/→ month  ↑/↓ year  q back",
+		"calendar_month":             "In %s you won %s, lost %d and missed %d.",
+		"day_won":                    "%s: won in %s.",
+		"day_lost":                   "%s: lost.",
+		"load_leaderboard_failed":    "failed to load the leaderboard",
+		"no_speedruns_today":         "nobody has solved today's speedrun yet",
+		"fastest_ever":               "fastest ever",
+		"replay_of":                  "Replay of puzzle %d.",
+		"not_finished_puzzle":        "you have not finished puzzle %d",
+		"imported_no_replay":         "puzzle %d was imported from a share grid, so there is nothing to replay",
+		"group_none":                 "you are not in a group; join one with: group join <name>",
+		"group_members":              "group %s: %s",
+		"group_joined":               "you joined group %s",
+		"group_left":                 "you left group %s",
+		"group_invalid":              "group names are 2 to 32 letters, digits and dashes",
+		"load_group_failed":          "failed to load your group",
+		"save_group_failed":          "failed to save your group",
+		"no_such_member":             "nobody called %s is in your group",
+		"ambiguous_member":           "more than one player called %s is in your group",
+		"member_no_games":            "%s has not finished a game yet",
+		"member_not_finished_puzzle": "%s has not finished puzzle %d",
+		"finish_today_first":         "finish today's puzzle before watching anyone else's",
+		"accessible_intro":           "Wordle. Guess the %d letter word in %d tries. Type %s at any time for a summary of what you know, or %s to hear how your month went.",
+		"guesses_left":               "%s left.",
+		"describe_guess":             "Guess %d, %s: %s.",
+		"letter_correct":             "%c correct",
+		"letter_present":             "%c present",
+		"letter_absent":              "%c absent",
+		"no_guesses_yet":             "No guesses yet. %s left.",
+		"ordinals":                   "first second third fourth fifth sixth seventh eighth",
+		"letter_at":                  "%c %s",
+		"letter_not_at":              "%c, not %s",
+		"or":                         " or ",
+		"in_place":                   "In place: %s.",
+		"not_placed":                 "In the word but not placed: %s.",
+		"not_in_word":                "Not in the word: %s.",
+		"played_and_won":             "You have played %s and won %d percent.",
+		"streaks":                    "Your current streak is %d and your longest streak is %d.",
+		"have_freezes":               "You have %s.",
+		"freezes_one":                "%d streak freeze",
+		"freezes_many":               "%d streak freezes",
+		"games_in":                   "%s in %s",
+		"you_won":                    "You won %s.",
+		"hours_one":                  "%d hour",
+		"hours_many":                 "%d hours",
+		"minutes_one":                "%d minute",
+		"minutes_many":               "%d minutes",
+		"next_wordle_in":             "The next Wordle is in %s and %s.",
+		"first_to_finish":            "You are the first to finish today's puzzle.",
+		"describe_puzzle":            "Today %s finished this puzzle and %d percent won, in %.1f guesses on average. You did better than %d percent of them.",
+		"players_one":                "%d player",
+		"players_many":               "%d players",
+		"mode_disabled":              "the %s mode is not enabled on this server",
+		"unknown_shortcut":           "unknown command %s, try %s",
+		"unknown_command":            "unknown command %q",
+		"load_settings_failed":       "failed to load settings",
+		"save_settings_failed":       "failed to save settings",
+		"theme_classic":              "green and yellow letters",
+		"theme_high-contrast":        "colour-blind friendly orange and blue letters",
+		"theme_mono":                 "no colour: [A] correct, (A) present,  A  absent",
+		"theme_blocks":               "256-colour tiles",
+		"theme_truecolor":            "24-bit colour tiles, like the web game",
+		"unknown_theme":              "unknown theme %q, choose one of: %s",
+		"theme_set":                  "theme set to %s %s",
+		"accessible_mode":            "accessible mode is %s",
+		"on":                         "on",
+		"off":                        "off",
+		"english_words":              "english words",
+		"own_words":                  "own words",
+		"unknown_language":           "unknown language %q, choose one of: %s",
+		"language_set":               "language set to %s",
+		"restarting":                 "The server is restarting in %s.",
+		"restarting_saved":           "The server is restarting in %s. Your game has been saved.",
+		"restarting_not_saved":       "The server is restarting in %s. Your game could not be saved.",
+	},
+	"es": {
+		"title":                      "Wordle",
+		"winner":                     "¡Ganaste!",
+		"statistics":                 "Estadísticas",
+		"played":                     "jugadas",
+		"win_percent":                "% victorias",
+		"current_streak":             "racha actual",
+		"max_streak":                 "mejor racha",
+		"streak_freezes":             "protectores de racha",
+		"guess_distribution":         "distribución",
+		"today":                      "hoy",
+		"today_summary":              "hoy: %d jugadas, %d%% ganadas, %.1f de media",
+		"today_short":                "hoy: %d%% de %d ganaron",
+		"percentile":                 "mejor que el %d%% de jugadores",
+		"percentile_short":           "mejor que el %d%%",
+		"first_today":                "primero en terminar hoy",
+		"next_wordle":                "Próximo Wordle en %d horas %d min",
+		"next_wordle_short":          "próximo %dh %dm",
+		"word_length":                "la palabra debe tener %d letras",
+		"invalid_word":               "palabra no válida %q",
+		"slow_down":                  "más despacio",
+		"press_key":                  "Pulsa una tecla para jugar",
+		"press_enter":                "Pulsa intro para jugar",
+		"speedrun":                   "Contrarreloj",
+		"speedrun_start":             "el reloj empieza al teclear",
+		"clock_started":              "el reloj está en marcha",
+		"time":                       "tiempo",
+		"splits":                     "parciales",
+		"not_solved":                 "sin resolver, sin tiempo",
+		"fastest_today":              "los más rápidos de hoy",
+		"replay_hint":                "/replay para verla otra vez, otra tecla para salir",
+		"replay_hint_short":          "/replay o una tecla",
+		"hint_used":                  "pidió una pista",
+		"left_game":                  "salió de la partida",
+		"came_back":                  "volvió",
+		"load_stats_failed":          "no se pudieron cargar tus estadísticas",
+		"load_games_failed":          "no se pudieron cargar tus partidas",
+		"no_games_yet":               "aún no has terminado ninguna partida",
+		"average_guesses":            "media de intentos",
+		"median_solve_time":          "tiempo mediano",
+		"favourite_opener":           "apertura favorita",
+		"best_word":                  "mejor palabra",
+		"worst_word":                 "peor palabra",
+		"word_in":                    "%s en %s",
+		"word_lost":                  "%s perdida",
+		"by_weekday":                 "por día de la semana",
+		"by_opener":                  "por apertura",
+		"by_first_letter":            "por primera letra",
+		"won_of":                     "%3d%% ganadas de %s",
+		"average":                    "%.1f intentos",
+		"games_one":                  "%d partida",
+		"games_many":                 "%d partidas",
+		"guesses_one":                "%d intento",
+		"guesses_many":               "%d intentos",
+		"wins_one":                   "%d victoria",
+		"wins_many":                  "%d victorias",
+		"weekdays":                   "domingo lunes martes miércoles jueves viernes sábado",
+		"months":                     "enero febrero marzo abril mayo junio julio agosto septiembre octubre noviembre diciembre",
+		"months_short":               "ene feb mar abr may jun jul ago sep oct nov dic",
+		"weekdays_short":             "dom lun mar mié jue vie sáb",
+		"month_year":                 "%s de %d",
+		"date":                       "%[2]d de %[1]s",
+		"calendar_summary":           "%s: %d ganadas, %d perdidas, %d sin jugar",
+		"calendar_legend":            "1-6 ganada en tantos intentos, x perdida, · sin jugar",
+		"calendar_help":              "←/→ mes  ↑/↓ año  q volver",
+		"calendar_month":             "En %s ganaste %s, perdiste %d y no jugaste %d.",
+		"day_won":                    "%s: ganada en %s.",
+		"day_lost":                   "%s: perdida.",
+		"load_leaderboard_failed":    "no se pudo cargar la clasificación",
+		"no_speedruns_today":         "nadie ha resuelto aún la contrarreloj de hoy",
+		"fastest_ever":               "los más rápidos de siempre",
+		"replay_of":                  "Repetición del Wordle %d.",
+		"not_finished_puzzle":        "no has terminado el Wordle %d",
+		"imported_no_replay":         "el Wordle %d se importó de una cuadrícula compartida, no hay nada que repetir",
+		"group_none":                 "no estás en ningún grupo; únete a uno con: group join <nombre>",
+		"group_members":              "grupo %s: %s",
+		"group_joined":               "te uniste al grupo %s",
+		"group_left":                 "saliste del grupo %s",
+		"group_invalid":              "los nombres de grupo tienen de 2 a 32 letras, cifras y guiones",
+		"load_group_failed":          "no se pudo cargar tu grupo",
+		"save_group_failed":          "no se pudo guardar tu grupo",
+		"no_such_member":             "nadie llamado %s está en tu grupo",
+		"ambiguous_member":           "hay más de un jugador llamado %s en tu grupo",
+		"member_no_games":            "%s aún no ha terminado ninguna partida",
+		"member_not_finished_puzzle": "%s no ha terminado el Wordle %d",
+		"finish_today_first":         "termina el Wordle de hoy antes de ver el de otros",
+		"accessible_intro":           "Wordle. Adivina la palabra de %d letras en %d intentos. Escribe %s en cualquier momento para oír lo que sabes, o %s para oír cómo te fue el mes.",
+		"guesses_left":               "Quedan %s.",
+		"describe_guess":             "Intento %d, %s: %s.",
+		"letter_correct":             "%c correcta",
+		"letter_present":             "%c presente",
+		"letter_absent":              "%c ausente",
+		"no_guesses_yet":             "Aún no hay intentos. Quedan %s.",
+		"ordinals":                   "primera segunda tercera cuarta quinta sexta séptima octava",
+		"letter_at":                  "%c %s",
+		"letter_not_at":              "%c, no %s",
+		"or":                         " ni ",
+		"in_place":                   "En su sitio: %s.",
+		"not_placed":                 "En la palabra pero no en su sitio: %s.",
+		"not_in_word":                "No están en la palabra: %s.",
+		"played_and_won":             "Has jugado %s y ganado el %d por ciento.",
+		"streaks":                    "Tu racha actual es %d y tu mejor racha es %d.",
+		"have_freezes":               "Tienes %s.",
+		"freezes_one":                "%d protector de racha",
+		"freezes_many":               "%d protectores de racha",
+		"games_in":                   "%s en %s",
+		"you_won":                    "Ganaste %s.",
+		"hours_one":                  "%d hora",
+		"hours_many":                 "%d horas",
+		"minutes_one":                "%d minuto",
+		"minutes_many":               "%d minutos",
+		"next_wordle_in":             "El próximo Wordle es en %s y %s.",
+		"first_to_finish":            "Eres el primero en terminar el Wordle de hoy.",
+		"describe_puzzle":            "Hoy %s terminaron este Wordle y ganó el %d por ciento, con %.1f intentos de media. Lo hiciste mejor que el %d por ciento.",
+		"players_one":                "%d jugador",
+		"players_many":               "%d jugadores",
+		"mode_disabled":              "el modo %s no está activado en este servidor",
+		"unknown_shortcut":           "orden desconocida %s, prueba %s",
+		"unknown_command":            "orden desconocida %q",
+		"load_settings_failed":       "no se pudieron cargar tus ajustes",
+		"save_settings_failed":       "no se pudieron guardar tus ajustes",
+		"theme_classic":              "letras verdes y amarillas",
+		"theme_high-contrast":        "letras naranjas y azules, aptas para daltónicos",
+		"theme_mono":                 "sin color: [A] correcta, (A) presente,  A  ausente",
+		"theme_blocks":               "casillas de 256 colores",
+		"theme_truecolor":            "casillas de 24 bits, como el juego web",
+		"unknown_theme":              "tema desconocido %q, elige uno de: %s",
+		"theme_set":                  "tema cambiado a %s %s",
+		"accessible_mode":            "el modo accesible está %s",
+		"on":                         "activado",
+		"off":                        "desactivado",
+		"english_words":              "palabras en inglés",
+		"own_words":                  "palabras propias",
+		"unknown_language":           "idioma desconocido %q, elige uno de: %s",
+		"language_set":               "idioma cambiado a %s",
+		"restarting":                 "El servidor se reinicia en %s.",
+		"restarting_saved":           "El servidor se reinicia en %s. Tu partida se ha guardado.",
+		"restarting_not_saved":       "El servidor se reinicia en %s. No se pudo guardar tu partida.",
+	},
+	"de": {
+		"title":                      "Wordle",
+		"winner":                     "Gewonnen!",
+		"statistics":                 "Statistik",
+		"played":                     "gespielt",
+		"win_percent":                "gewonnen %",
+		"current_streak":             "aktuelle Serie",
+		"max_streak":                 "beste Serie",
+		"streak_freezes":             "Serienschutz",
+		"guess_distribution":         "Verteilung",
+		"today":                      "heute",
+		"today_summary":              "heute: %d gespielt, %d%% gewonnen, Ø %.1f",
+		"today_short":                "heute: %d%% von %d gewonnen",
+		"percentile":                 "besser als %d%% der Spieler",
+		"percentile_short":           "besser als %d%%",
+		"first_today":                "heute als Erstes fertig",
+		"next_wordle":                "Nächstes Wordle in %d Std. %d Min.",
+		"next_wordle_short":          "nächstes %dh %dm",
+		"word_length":                "das Wort muss %d Buchstaben haben",
+		"invalid_word":               "ungültiges Wort %q",
+		"slow_down":                  "langsamer",
+		"press_key":                  "Drücke eine Taste zum Spielen",
+		"press_enter":                "Drücke Enter zum Spielen",
+		"speedrun":                   "Speedrun",
+		"speedrun_start":             "die Uhr startet beim Tippen",
+		"clock_started":              "die Uhr läuft",
+		"time":                       "Zeit",
+		"splits":                     "Zwischenzeiten",
+		"not_solved":                 "nicht gelöst, keine Zeit",
+		"fastest_today":              "heute am schnellsten",
+		"replay_hint":                "/replay zum Ansehen, andere Taste zum Beenden",
+		"replay_hint_short":          "/replay oder Taste",
+		"hint_used":                  "Hinweis genutzt",
+		"left_game":                  "Spiel verlassen",
+		"came_back":                  "zurückgekehrt",
+		"load_stats_failed":          "Statistik konnte nicht geladen werden",
+		"load_games_failed":          "Spiele konnten nicht geladen werden",
+		"no_games_yet":               "du hast noch kein Spiel beendet",
+		"average_guesses":            "Versuche im Schnitt",
+		"median_solve_time":          "mittlere Lösungszeit",
+		"favourite_opener":           "Lieblingsstart",
+		"best_word":                  "bestes Wort",
+		"worst_word":                 "schlechtestes Wort",
+		"word_in":                    "%s, %s",
+		"word_lost":                  "%s verloren",
+		"by_weekday":                 "nach Wochentag",
+		"by_opener":                  "nach Startwort",
+		"by_first_letter":            "nach Anfangsbuchstabe",
+		"won_of":                     "%3d%% gewonnen von %s",
+		"average":                    "Ø %.1f Versuche",
+		"games_one":                  "%d Spiel",
+		"games_many":                 "%d Spiele",
+		"guesses_one":                "%d Versuch",
+		"guesses_many":               "%d Versuche",
+		"wins_one":                   "%d Sieg",
+		"wins_many":                  "%d Siege",
+		"weekdays":                   "Sonntag Montag Dienstag Mittwoch Donnerstag Freitag Samstag",
+		"months":                     "Januar Februar März April Mai Juni Juli August September Oktober November Dezember",
+		"months_short":               "Jan Feb Mär Apr Mai Jun Jul Aug Sep Okt Nov Dez",
+		"weekdays_short":             "So Mo Di Mi Do Fr Sa",
+		"month_year":                 "%s %d",
+		"date":                       "%[2]d. %[1]s",
+		"calendar_summary":           "%s: %d gewonnen, %d verloren, %d verpasst",
+		"calendar_legend":            "1-6 gewonnen mit so vielen Versuchen, x verloren, · verpasst",
+		"calendar_help":              "←/→ Monat  ↑/↓ Jahr  q zurück",
+		"calendar_month":             "Im %s hast du %s gewonnen, %d verloren und %d verpasst.",
+		"day_won":                    "%s: gewonnen, %s.",
+		"day_lost":                   "%s: verloren.",
+		"load_leaderboard_failed":    "Bestenliste konnte nicht geladen werden",
+		"no_speedruns_today":         "heute hat noch niemand den Speedrun gelöst",
+		"fastest_ever":               "am schnellsten überhaupt",
+		"replay_of":                  "Wiederholung von Wordle %d.",
+		"not_finished_puzzle":        "du hast Wordle %d nicht beendet",
+		"imported_no_replay":         "Wordle %d wurde aus einem geteilten Raster importiert, es gibt nichts zu wiederholen",
+		"group_none":                 "du bist in keiner Gruppe; tritt einer bei mit: group join <name>",
+		"group_members":              "Gruppe %s: %s",
+		"group_joined":               "du bist der Gruppe %s beigetreten",
+		"group_left":                 "du hast die Gruppe %s verlassen",
+		"group_invalid":              "Gruppennamen haben 2 bis 32 Buchstaben, Ziffern und Bindestriche",
+		"load_group_failed":          "Gruppe konnte nicht geladen werden",
+		"save_group_failed":          "Gruppe konnte nicht gespeichert werden",
+		"no_such_member":             "niemand namens %s ist in deiner Gruppe",
+		"ambiguous_member":           "mehrere Spieler namens %s sind in deiner Gruppe",
+		"member_no_games":            "%s hat noch kein Spiel beendet",
+		"member_not_finished_puzzle": "%s hat Wordle %d nicht beendet",
+		"finish_today_first":         "beende das heutige Wordle, bevor du dir andere ansiehst",
+		"accessible_intro":           "Wordle. Errate das Wort mit %d Buchstaben in %d Versuchen. Tippe jederzeit %s für eine Zusammenfassung deines Wissens oder %s, um zu hören, wie dein Monat lief.",
+		"guesses_left":               "Noch %s.",
+		"describe_guess":             "Versuch %d, %s: %s.",
+		"letter_correct":             "%c richtig",
+		"letter_present":             "%c enthalten",
+		"letter_absent":              "%c nicht enthalten",
+		"no_guesses_yet":             "Noch keine Versuche. Noch %s.",
+		"ordinals":                   "erste zweite dritte vierte fünfte sechste siebte achte",
+		"letter_at":                  "%c %s",
+		"letter_not_at":              "%c, nicht %s",
+		"or":                         " oder ",
+		"in_place":                   "An der richtigen Stelle: %s.",
+		"not_placed":                 "Im Wort, aber nicht platziert: %s.",
+		"not_in_word":                "Nicht im Wort: %s.",
+		"played_and_won":             "Du hast %s gespielt und %d Prozent gewonnen.",
+		"streaks":                    "Deine aktuelle Serie ist %d und deine beste Serie %d.",
+		"have_freezes":               "Du hast %s.",
+		"freezes_one":                "%d Schutztag",
+		"freezes_many":               "%d Schutztage",
+		"games_in":                   "%s mit %s",
+		"you_won":                    "Du hast %s gewonnen.",
+		"hours_one":                  "%d Stunde",
+		"hours_many":                 "%d Stunden",
+		"minutes_one":                "%d Minute",
+		"minutes_many":               "%d Minuten",
+		"next_wordle_in":             "Das nächste Wordle kommt in %s und %s.",
+		"first_to_finish":            "Du bist heute als Erstes mit diesem Wordle fertig.",
+		"describe_puzzle":            "Heute haben %s dieses Wordle beendet und %d Prozent gewonnen, mit durchschnittlich %.1f Versuchen. Du warst besser als %d Prozent von ihnen.",
+		"players_one":                "%d Spieler",
+		"players_many":               "%d Spieler",
+		"mode_disabled":              "der Modus %s ist auf diesem Server nicht aktiviert",
+		"unknown_shortcut":           "unbekannter Befehl %s, versuche %s",
+		"unknown_command":            "unbekannter Befehl %q",
+		"load_settings_failed":       "Einstellungen konnten nicht geladen werden",
+		"save_settings_failed":       "Einstellungen konnten nicht gespeichert werden",
+		"theme_classic":              "grüne und gelbe Buchstaben",
+		"theme_high-contrast":        "farbenblindfreundliche orange und blaue Buchstaben",
+		"theme_mono":                 "keine Farbe: [A] richtig, (A) enthalten,  A  nicht enthalten",
+		"theme_blocks":               "Felder mit 256 Farben",
+		"theme_truecolor":            "Felder mit 24-Bit-Farben, wie im Webspiel",
+		"unknown_theme":              "unbekanntes Thema %q, wähle eines von: %s",
+		"theme_set":                  "Thema auf %s %s gesetzt",
+		"accessible_mode":            "der barrierefreie Modus ist %s",
+		"on":                         "an",
+		"off":                        "aus",
+		"english_words":              "englische Wörter",
+		"own_words":                  "eigene Wörter",
+		"unknown_language":           "unbekannte Sprache %q, wähle eine von: %s",
+		"language_set":               "Sprache auf %s gesetzt",
+		"restarting":                 "Der Server startet in %s neu.",
+		"restarting_saved":           "Der Server startet in %s neu. Dein Spiel wurde gespeichert.",
+		"restarting_not_saved":       "Der Server startet in %s neu. Dein Spiel konnte nicht gespeichert werden.",
+	},
+	"fr": {
+		"title":                      "Wordle",
+		"winner":                     "Gagné !",
+		"statistics":                 "Statistiques",
+		"played":                     "parties",
+		"win_percent":                "% victoires",
+		"current_streak":             "série actuelle",
+		"max_streak":                 "meilleure série",
+		"streak_freezes":             "protections de série",
+		"guess_distribution":         "répartition",
+		"today":                      "aujourd'hui",
+		"today_summary":              "aujourd'hui : %d parties, %d%% gagnées, %.1f en moyenne",
+		"today_short":                "auj. : %d%% de %d gagnées",
+		"percentile":                 "mieux que %d%% des joueurs",
+		"percentile_short":           "mieux que %d%%",
+		"first_today":                "premier à finir aujourd'hui",
+		"next_wordle":                "Prochain Wordle dans %d h %d min",
+		"next_wordle_short":          "prochain %dh %dm",
+		"word_length":                "le mot doit faire %d lettres",
+		"invalid_word":               "mot invalide %q",
+		"slow_down":                  "doucement",
+		"press_key":                  "Appuyez sur une touche pour jouer",
+		"press_enter":                "Appuyez sur Entrée pour jouer",
+		"speedrun":                   "Contre-la-montre",
+		"speedrun_start":             "le chrono démarre à la frappe",
+		"clock_started":              "le chrono tourne",
+		"time":                       "temps",
+		"splits":                     "temps intermédiaires",
+		"not_solved":                 "non résolu, pas de temps",
+		"fastest_today":              "les plus rapides du jour",
+		"replay_hint":                "/replay pour revoir, une touche pour quitter",
+		"replay_hint_short":          "/replay ou une touche",
+		"hint_used":                  "indice demandé",
+		"left_game":                  "a quitté la partie",
+		"came_back":                  "est revenu",
+		"load_stats_failed":          "impossible de charger vos statistiques",
+		"load_games_failed":          "impossible de charger vos parties",
+		"no_games_yet":               "vous n'avez pas encore terminé de partie",
+		"average_guesses":            "essais en moyenne",
+		"median_solve_time":          "temps de résolution médian",
+		"favourite_opener":           "ouverture préférée",
+		"best_word":                  "meilleur mot",
+		"worst_word":                 "pire mot",
+		"word_in":                    "%s en %s",
+		"word_lost":                  "%s perdu",
+		"by_weekday":                 "par jour de la semaine",
+		"by_opener":                  "par ouverture",
+		"by_first_letter":            "par première lettre",
+		"won_of":                     "%3d%% gagnées sur %s",
+		"average":                    "%.1f essais",
+		"games_one":                  "%d partie",
+		"games_many":                 "%d parties",
+		"guesses_one":                "%d essai",
+		"guesses_many":               "%d essais",
+		"wins_one":                   "%d victoire",
+		"wins_many":                  "%d victoires",
+		"weekdays":                   "dimanche lundi mardi mercredi jeudi vendredi samedi",
+		"months":                     "janvier février mars avril mai juin juillet août septembre octobre novembre décembre",
+		"months_short":               "jan fév mar avr mai jun jul aoû sep oct nov déc",
+		"weekdays_short":             "dim lun mar mer jeu ven sam",
+		"month_year":                 "%s %d",
+		"date":                       "%[2]d %[1]s",
+		"calendar_summary":           "%s : %d gagnées, %d perdues, %d manquées",
+		"calendar_legend":            "1-6 gagnée en autant d'essais, x perdue, · manquée",
+		"calendar_help":              "←/→ mois  ↑/↓ année  q retour",
+		"calendar_month":             "En %s, vous avez gagné %s, perdu %d et manqué %d.",
+		"day_won":                    "%s : gagnée en %s.",
+		"day_lost":                   "%s : perdue.",
+		"load_leaderboard_failed":    "impossible de charger le classement",
+		"no_speedruns_today":         "personne n'a encore résolu le contre-la-montre du jour",
+		"fastest_ever":               "les plus rapides de tous les temps",
+		"replay_of":                  "Rediffusion du Wordle %d.",
+		"not_finished_puzzle":        "vous n'avez pas terminé le Wordle %d",
+		"imported_no_replay":         "le Wordle %d a été importé d'une grille partagée, rien à revoir",
+		"group_none":                 "vous n'êtes dans aucun groupe ; rejoignez-en un avec : group join <nom>",
+		"group_members":              "groupe %s : %s",
+		"group_joined":               "vous avez rejoint le groupe %s",
+		"group_left":                 "vous avez quitté le groupe %s",
+		"group_invalid":              "les noms de groupe font de 2 à 32 lettres, chiffres et tirets",
+		"load_group_failed":          "impossible de charger votre groupe",
+		"save_group_failed":          "impossible d'enregistrer votre groupe",
+		"no_such_member":             "personne du nom de %s n'est dans votre groupe",
+		"ambiguous_member":           "plusieurs joueurs du nom de %s sont dans votre groupe",
+		"member_no_games":            "%s n'a encore terminé aucune partie",
+		"member_not_finished_puzzle": "%s n'a pas terminé le Wordle %d",
+		"finish_today_first":         "terminez le Wordle du jour avant de regarder celui des autres",
+		"accessible_intro":           "Wordle. Devinez le mot de %d lettres en %d essais. Tapez %s à tout moment pour un résumé de ce que vous savez, ou %s pour entendre comment s'est passé votre mois.",
+		"guesses_left":               "Il reste %s.",
+		"describe_guess":             "Essai %d, %s : %s.",
+		"letter_correct":             "%c bien placée",
+		"letter_present":             "%c mal placée",
+		"letter_absent":              "%c absente",
+		"no_guesses_yet":             "Aucun essai pour l'instant. Il reste %s.",
+		"ordinals":                   "première deuxième troisième quatrième cinquième sixième septième huitième",
+		"letter_at":                  "%c %s",
+		"letter_not_at":              "%c, pas %s",
+		"or":                         " ni ",
+		"in_place":                   "Bien placées : %s.",
+		"not_placed":                 "Dans le mot mais mal placées : %s.",
+		"not_in_word":                "Absentes du mot : %s.",
+		"played_and_won":             "Vous avez joué %s et gagné %d pour cent.",
+		"streaks":                    "Votre série actuelle est de %d et votre meilleure série de %d.",
+		"have_freezes":               "Vous avez %s.",
+		"freezes_one":                "%d protection de série",
+		"freezes_many":               "%d protections de série",
+		"games_in":                   "%s en %s",
+		"you_won":                    "Vous avez gagné %s.",
+		"hours_one":                  "%d heure",
+		"hours_many":                 "%d heures",
+		"minutes_one":                "%d minute",
+		"minutes_many":               "%d minutes",
+		"next_wordle_in":             "Le prochain Wordle arrive dans %s et %s.",
+		"first_to_finish":            "Vous êtes le premier à finir le Wordle du jour.",
+		"describe_puzzle":            "Aujourd'hui, %s ont fini ce Wordle et %d pour cent l'ont gagné, en %.1f essais en moyenne. Vous avez fait mieux que %d pour cent d'entre eux.",
+		"players_one":                "%d joueur",
+		"players_many":               "%d joueurs",
+		"mode_disabled":              "le mode %s n'est pas activé sur ce serveur",
+		"unknown_shortcut":           "commande inconnue %s, essayez %s",
+		"unknown_command":            "commande inconnue %q",
+		"load_settings_failed":       "impossible de charger vos réglages",
+		"save_settings_failed":       "impossible d'enregistrer vos réglages",
+		"theme_classic":              "lettres vertes et jaunes",
+		"theme_high-contrast":        "lettres orange et bleues, adaptées aux daltoniens",
+		"theme_mono":                 "sans couleur : [A] bien placée, (A) mal placée,  A  absente",
+		"theme_blocks":               "cases en 256 couleurs",
+		"theme_truecolor":            "cases en couleurs 24 bits, comme le jeu web",
+		"unknown_theme":              "thème inconnu %q, choisissez parmi : %s",
+		"theme_set":                  "thème changé en %s %s",
+		"accessible_mode":            "le mode accessible est %s",
+		"on":                         "activé",
+		"off":                        "désactivé",
+		"english_words":              "mots anglais",
+		"own_words":                  "mots propres",
+		"unknown_language":           "langue inconnue %q, choisissez parmi : %s",
+		"language_set":               "langue changée en %s",
+		"restarting":                 "Le serveur redémarre dans %s.",
+		"restarting_saved":           "Le serveur redémarre dans %s. Votre partie a été enregistrée.",
+		"restarting_not_saved":       "Le serveur redémarre dans %s. Votre partie n'a pas pu être enregistrée.",
+	},
+}
+
+// localeNames returns the supported locales, sorted.
+func localeNames() []string {
+	names := make([]string, 0, len(catalogs))
+	for name := range catalogs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// translate formats the message key in locale.
+func translate(locale, key string, args ...interface{}) string {
+	format, ok := catalogs[locale][key]
+	if !ok {
+		format, ok = catalogs[defaultLocale][key]
+	}
+	if !ok {
+		format = key
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// t formats the message key in the player's locale.
+func (v *view) t(key string, args ...interface{}) string {
+	return translate(v.locale, key, args...)
+}
+
+// plural formats n with the message key_one or key_many in the player's
+// locale, e.g. plural(3, "games") for "3 games".
+func (v *view) plural(n int, key string) string {
+	return translatePlural(v.locale, n, key)
+}
+
+// translatePlural formats n with the message key_one or key_many in locale.
+func translatePlural(locale string, n int, key string) string {
+	if n == 1 {
+		return translate(locale, key+"_one", n)
+	}
+	return translate(locale, key+"_many", n)
+}
+
+// translateNth returns the i'th of the space-separated words of the message
+// key in locale, such as a weekday's name.
+func translateNth(locale, key string, i int) string {
+	words := strings.Fields(translate(locale, key))
+	if i < 0 || i >= len(words) {
+		return ""
+	}
+	return words[i]
+}
+
+// errorText translates a rejected guess's error for the player.
+func (v *view) errorText(err error, word string) string {
+	switch {
+	case errors.Is(err, ErrWordLength):
+		return v.t("word_length", WordLength)
+	case errors.Is(err, ErrInvalidWord):
+		return v.t("invalid_word", word)
+	case errors.Is(err, ErrTooManyGuesses):
+		return v.t("slow_down")
+	}
+	return err.Error()
+}
+
+// keptLetters are accented letters that count as letters of their own in a
+// language's word game rather than being folded to their base letter.
+var keptLetters = map[string]string{
+	"es": "ñ",
+	"de": "äöüß",
+}
+
+var foldedLetters = map[rune]rune{
+	'á': 'a', 'à': 'a', 'â': 'a', 'ä': 'a', 'ã': 'a', 'å': 'a',
+	'é': 'e', 'è': 'e', 'ê': 'e', 'ë': 'e',
+	'í': 'i', 'ì': 'i', 'î': 'i', 'ï': 'i',
+	'ó': 'o', 'ò': 'o', 'ô': 'o', 'ö': 'o', 'õ': 'o',
+	'ú': 'u', 'ù': 'u', 'û': 'u', 'ü': 'u',
+	'ç': 'c', 'ñ': 'n', 'ÿ': 'y',
+}
+
+// normalize lowercases word and folds accented letters to their base letter,
+// except those that are distinct letters in lang, so that "ÉLÈVE" and
+// "eleve" are the same French guess.
+func normalize(lang, word string) string {
+	word = strings.ToLower(strings.TrimSpace(word))
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(keptLetters[lang], r) {
+			return r
+		}
+		if base, ok := foldedLetters[r]; ok {
+			return base
+		}
+		return r
+	}, word)
+}
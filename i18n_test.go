@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "eleve", normalize("fr", " ÉLÈVE "))
+	assert.Equal(t, "niño", normalize("es", "NIÑO"))
+	assert.Equal(t, "nino", normalize("fr", "niño"))
+	assert.Equal(t, "größe", normalize("de", "Größe"))
+}
+
+func TestGuessInLanguage(t *testing.T) {
+	defer setDictionaries(dictionaries)
+	setDictionaries(map[string]*dictionary{
+		defaultLocale: dictionaryFor(defaultLocale),
+		"es":          newDictionary("es", []string{"cañón"}, []string{"añejo", "canon"}),
+	})
+
+	game := NewGame("cañon")
+	game.Lang = "es"
+
+	err, won := game.Guess("añejo")
+	require.NoError(t, err)
+	assert.False(t, won)
+	assert.Equal(t, []letterState{letterPresent, letterPresent, letterAbsent, letterAbsent, letterPresent}, game.Score("añejo"))
+
+	err, _ = game.Guess("water")
+	assert.ErrorIs(t, err, ErrInvalidWord)
+
+	err, won = game.Guess("CAÑÓN")
+	assert.ErrorIs(t, err, ErrGameOver)
+	assert.True(t, won)
+}
+
+func TestWordOfTheDayInLanguage(t *testing.T) {
+	es := newDictionary("es", []string{"perro", "gatos", "raton"}, nil)
+	assert.Equal(t, ultraDate, es.FirstDay())
+	assert.Equal(t, "perro", es.WordOfTheDay(ultraDate.Add(time.Hour)))
+	assert.Equal(t, "raton", es.WordOfTheDay(ultraDate.Add(-time.Hour)))
+	assert.Equal(t, "gatos", es.WordOfTheDay(ultraDate.AddDate(0, 0, -2)))
+
+	// Share grids from before the list starts can't be imported.
+	repo, err := newRepo(filepath.Join(t.TempDir(), "wordle.db"), StreakConfig{})
+	require.NoError(t, err)
+	defer repo.Close()
+	_, err = readShareGames(context.Background(), repo, strings.NewReader("Wordle 5 1/6\n\n🟩🟩🟩🟩🟩\n"), "alice", es)
+	assert.Error(t, err)
+
+	en := dictionaryFor(defaultLocale)
+	assert.Equal(t, puzzleEpoch, en.FirstDay())
+	assert.Equal(t, en.WordOfTheDay(puzzleDate(5)), en.WordOfTheDay(puzzleDate(5).Add(12*time.Hour)))
+}
+
+func TestTranslate(t *testing.T) {
+	assert.Equal(t, "Gewonnen!", translate("de", "winner"))
+	assert.Equal(t, "la palabra debe tener 5 letras", translate("es", "word_length", 5))
+	assert.Equal(t, "Winner!", translate("xx", "winner"))
+
+	v := &view{locale: "fr"}
+	assert.Equal(t, "1 partie", v.plural(1, "games"))
+	assert.Equal(t, "3 parties", v.plural(3, "games"))
+	assert.Equal(t, "lundi", translateNth("fr", "weekdays", int(time.Monday)))
+	assert.Equal(t, "Monday", translateNth("xx", "weekdays", int(time.Monday)))
+}
+
+func TestCatalogsComplete(t *testing.T) {
+	for locale, messages := range catalogs {
+		for key := range catalogs[defaultLocale] {
+			assert.Contains(t, messages, key, "%s is missing %s", locale, key)
+		}
+		for key := range messages {
+			assert.Contains(t, catalogs[defaultLocale], key, "%s has %s, which English lacks", locale, key)
+		}
+	}
+
+	// Every theme is described, in every language.
+	for _, theme := range themes {
+		assert.Contains(t, catalogs[defaultLocale], "theme_"+theme.Name)
+	}
+	for _, locale := range localeNames() {
+		assert.GreaterOrEqual(t, len(strings.Fields(translate(locale, "ordinals"))), WordLength, locale)
+	}
+}
+
+func TestAccessibleInLanguage(t *testing.T) {
+	v := &view{locale: "es"}
+	game := NewGame("juice")
+	game.Guesses = []string{"crane", "slice"}
+
+	assert.Equal(t, "Intento 1, crane: C presente, R ausente, A ausente, N ausente, E correcta.", describeGuess(v, game, 0))
+	assert.Equal(t,
+		"En su sitio: I tercera, C cuarta, E quinta. No están en la palabra: A, L, N, R, S. Quedan 4 intentos.",
+		summarize(v, game),
+	)
+}
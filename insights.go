@@ -0,0 +1,318 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Record counts the games and wins of some group of games, along with the
+// guesses the wins took.
+type Record struct {
+	Played  int
+	Won     int
+	Guesses int
+}
+
+func (r *Record) add(game Game) {
+	r.Played++
+	if game.Won {
+		r.Won++
+		r.Guesses += len(game.Guesses)
+	}
+}
+
+// WinPercent returns the share of games won, rounded down.
+func (r Record) WinPercent() int {
+	if r.Played == 0 {
+		return 0
+	}
+	return r.Won * 100 / r.Played
+}
+
+// AverageGuesses returns the mean number of guesses the wins took.
+func (r Record) AverageGuesses() float64 {
+	if r.Won == 0 {
+		return 0
+	}
+	return float64(r.Guesses) / float64(r.Won)
+}
+
+// finished returns the games that are over, oldest first.
+func (games Games) finished() Games {
+	var done Games
+	for _, game := range games {
+		if game.IsDone() {
+			done = append(done, game)
+		}
+	}
+	sort.SliceStable(done, func(i, j int) bool { return done[i].Started.Before(done[j].Started) })
+	return done
+}
+
+// AverageGuesses returns the mean number of guesses of the games won.
+func (games Games) AverageGuesses() float64 {
+	return games.record().AverageGuesses()
+}
+
+// MedianSolveTime returns the median time from the first guess screen to the
+// win, or zero if no win was timed. Imported games have no times.
+func (games Games) MedianSolveTime() time.Duration {
+	var times []time.Duration
+	for _, game := range games.finished() {
+		if game.Won && !game.Imported && game.Finished.After(game.Started) {
+			times = append(times, game.Finished.Sub(game.Started))
+		}
+	}
+	if len(times) == 0 {
+		return 0
+	}
+
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	mid := len(times) / 2
+	if len(times)%2 == 0 {
+		return (times[mid-1] + times[mid]) / 2
+	}
+	return times[mid]
+}
+
+// ByWeekday returns the record of the puzzles of each day of the week,
+// starting on Sunday.
+func (games Games) ByWeekday() [7]Record {
+	var days [7]Record
+	for _, game := range games.finished() {
+		days[game.Started.UTC().Weekday()].add(game)
+	}
+	return days
+}
+
+// ByOpener returns the record of each opening word. Games imported from
+// share grids are left out, since their guesses are unknown.
+func (games Games) ByOpener() map[string]Record {
+	return games.groupBy(func(game Game) string { return game.Guesses[0] })
+}
+
+// ByFirstLetter returns the record of each first letter of the opening word.
+func (games Games) ByFirstLetter() map[string]Record {
+	return games.groupBy(func(game Game) string { return string([]rune(game.Guesses[0])[0]) })
+}
+
+func (games Games) groupBy(key func(Game) string) map[string]Record {
+	records := make(map[string]Record)
+	for k, group := range games.split(func(game Game) string {
+		if game.Guesses[0] == unknownGuess {
+			return ""
+		}
+		return key(game)
+	}) {
+		records[k] = group.record()
+	}
+	return records
+}
+
+// split groups the finished games by key, oldest first, leaving out the
+// games key returns "" for.
+func (games Games) split(key func(Game) string) map[string]Games {
+	groups := make(map[string]Games)
+	for _, game := range games.finished() {
+		if k := key(game); k != "" {
+			groups[k] = append(groups[k], game)
+		}
+	}
+	return groups
+}
+
+// record returns the record of the finished games.
+func (games Games) record() Record {
+	var r Record
+	for _, game := range games.finished() {
+		r.add(game)
+	}
+	return r
+}
+
+// MostCommonOpener returns the opening word played most often, the latest
+// used on a tie, and how many times it was played.
+func (games Games) MostCommonOpener() (string, int) {
+	var (
+		counts = make(map[string]int)
+		word   string
+	)
+	for _, game := range games.finished() {
+		opener := game.Guesses[0]
+		if opener == unknownGuess {
+			continue
+		}
+		counts[opener]++
+		if counts[opener] >= counts[word] {
+			word = opener
+		}
+	}
+	return word, counts[word]
+}
+
+// BestWord returns the answer found in the fewest guesses, the latest on a
+// tie.
+func (games Games) BestWord() (Game, bool) {
+	best, ok := games.pick(func(game, best Game) bool {
+		return game.Won && (!best.Won || len(game.Guesses) <= len(best.Guesses))
+	})
+	return best, ok && best.Won
+}
+
+// WorstWord returns the answer of the latest game lost, or if none were the
+// one that took the most guesses.
+func (games Games) WorstWord() (Game, bool) {
+	return games.pick(func(game, worst Game) bool {
+		switch {
+		case !game.Won:
+			return true
+		case !worst.Won:
+			return false
+		}
+		return len(game.Guesses) >= len(worst.Guesses)
+	})
+}
+
+// pick returns the finished game for which better reports true against
+// every game before it.
+func (games Games) pick(better func(game, best Game) bool) (Game, bool) {
+	var (
+		best  Game
+		found bool
+	)
+	for _, game := range games.finished() {
+		if !found || better(game, best) {
+			best, found = game, true
+		}
+	}
+	return best, found
+}
+
+// histogram draws the guess distribution as bars in proportion to the most
+// common row, highlighting the row of the game just played, if any.
+func histogram(t *theme, dist [MaxGuesses]int, latest, width int) []string {
+	most := 1
+	for _, n := range dist {
+		if n > most {
+			most = n
+		}
+	}
+
+	lines := make([]string, 0, len(dist))
+	for i, n := range dist {
+		bar := strings.Repeat("█", 1+n*(width-1)/most)
+		if i+1 == latest {
+			bar = t.paint(t.Correct, bar)
+		} else {
+			bar = t.paint(t.Absent, bar)
+		}
+		lines = append(lines, fmt.Sprintf("%d %s %d", i+1, bar, n))
+	}
+	return lines
+}
+
+// statsCommand shows the player's statistics in detail, e.g.
+// `ssh host stats`.
+func statsCommand(ctx context.Context, v *view, repo *sqliteRepo, user string) int {
+	lang := dictionaryFor(v.locale).Lang
+	stats, err := repo.PlayerStats(ctx, user, lang)
+	if err != nil {
+		logs.Error("player_stats_failed", "player", user, "err", err)
+		print(v, v.t("load_stats_failed")+"\n")
+		return 1
+	}
+	games, err := repo.ListGames(ctx, user)
+	if err != nil {
+		logs.Error("list_games_failed", "player", user, "err", err)
+		print(v, v.t("load_games_failed")+"\n")
+		return 1
+	}
+	games = games.InLang(lang)
+	if stats.Played == 0 {
+		print(v, v.t("no_games_yet")+"\n")
+		return 0
+	}
+
+	row := func(key, value string) {
+		print(v, fmt.Sprintf("%-20s %s\n", v.t(key), value))
+	}
+	row("played", fmt.Sprint(stats.Played))
+	row("win_percent", fmt.Sprint(stats.WinPercent()))
+	row("current_streak", fmt.Sprint(stats.Streak(time.Now())))
+	row("max_streak", fmt.Sprint(stats.MaxStreak))
+	if stats.Freezes > 0 {
+		row("streak_freezes", fmt.Sprint(stats.Freezes))
+	}
+	row("average_guesses", fmt.Sprintf("%.1f", games.AverageGuesses()))
+	if median := games.MedianSolveTime(); median > 0 {
+		row("median_solve_time", median.Round(time.Second).String())
+	}
+	if word, n := games.MostCommonOpener(); n > 0 {
+		row("favourite_opener", fmt.Sprintf("%s (%s)", word, v.plural(n, "games")))
+	}
+	if game, ok := games.BestWord(); ok {
+		row("best_word", v.t("word_in", game.Answer, v.plural(len(game.Guesses), "guesses")))
+	}
+	if game, ok := games.WorstWord(); ok {
+		result := v.t("word_lost", game.Answer)
+		if game.Won {
+			result = v.t("word_in", game.Answer, v.plural(len(game.Guesses), "guesses"))
+		}
+		row("worst_word", result)
+	}
+
+	print(v, "\n"+v.t("guess_distribution")+"\n")
+	if v.accessible {
+		for i, n := range stats.Distribution {
+			print(v, fmt.Sprintf("%s: %s\n", v.plural(i+1, "guesses"), v.plural(n, "wins")))
+		}
+	} else {
+		for _, line := range histogram(v.theme, stats.Distribution, 0, 20) {
+			print(v, "  "+line+"\n")
+		}
+	}
+
+	print(v, "\n"+v.t("by_weekday")+"\n")
+	days := games.ByWeekday()
+	for i := range days {
+		// Start the week on Monday.
+		day := (i + 1) % 7
+		if r := days[day]; r.Played > 0 {
+			print(v, fmt.Sprintf("  %-10s %s\n", translateNth(v.locale, "weekdays", day), v.t("won_of", r.WinPercent(), v.plural(r.Played, "games"))))
+		}
+	}
+
+	printRecords(v, v.t("by_opener"), games.ByOpener())
+	printRecords(v, v.t("by_first_letter"), games.ByFirstLetter())
+	return 0
+}
+
+// printRecords lists the five most played groups of records.
+func printRecords(v *view, title string, records map[string]Record) {
+	if len(records) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(records))
+	for k := range records {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := records[keys[i]], records[keys[j]]
+		if a.Played != b.Played {
+			return a.Played > b.Played
+		}
+		return keys[i] < keys[j]
+	})
+	if len(keys) > 5 {
+		keys = keys[:5]
+	}
+
+	print(v, "\n"+title+"\n")
+	for _, k := range keys {
+		r := records[k]
+		print(v, fmt.Sprintf("  %-10s %-28s %s\n", k, v.t("won_of", r.WinPercent(), v.plural(r.Played, "games")), v.t("average", r.AverageGuesses())))
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInsights(t *testing.T) {
+	monday := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	game := func(day int, answer string, won bool, solve time.Duration, guesses ...string) Game {
+		started := monday.AddDate(0, 0, day)
+		return Game{Answer: answer, Guesses: guesses, Won: won, Started: started, Finished: started.Add(solve)}
+	}
+	games := Games{
+		game(0, "ultra", true, time.Minute, "crane", "ultra"),
+		game(1, "water", true, 3*time.Minute, "crane", "slate", "water"),
+		game(2, "juice", false, 5*time.Minute, "slate", "crane", "crane", "crane", "crane", "crane"),
+		game(3, "onion", true, 2*time.Minute, "crane", "onion"),
+		{Answer: "today", Guesses: []string{"adieu"}, Started: monday.AddDate(0, 0, 4)},
+	}
+
+	assert.InDelta(t, 7.0/3, games.AverageGuesses(), 0.001)
+	assert.Equal(t, 2*time.Minute, games.MedianSolveTime())
+	assert.Equal(t, Record{Played: 1, Won: 0}, games.ByWeekday()[time.Wednesday])
+
+	opener, n := games.MostCommonOpener()
+	assert.Equal(t, "crane", opener)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, Record{Played: 3, Won: 3, Guesses: 7}, games.ByOpener()["crane"])
+	assert.Equal(t, 2, len(games.ByFirstLetter()))
+
+	best, ok := games.BestWord()
+	assert.True(t, ok)
+	assert.Equal(t, "onion", best.Answer)
+	worst, ok := games.WorstWord()
+	assert.True(t, ok)
+	assert.Equal(t, "juice", worst.Answer)
+}
+
+func TestHistogram(t *testing.T) {
+	lines := histogram(classicTheme, [MaxGuesses]int{0, 2, 4, 1, 0, 0}, 3, 9)
+	assert.Equal(t, "1 █ 0", lines[0])
+	assert.Equal(t, "2 █████ 2", lines[1])
+	assert.Equal(t, "3 \033[32m█████████\033[0m 4", lines[2])
+	assert.Equal(t, "4 ███ 1", lines[3])
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+type logLevel int
+
+const (
+	levelDebug logLevel = iota
+	levelInfo
+	levelWarn
+	levelError
+)
+
+func (l logLevel) String() string {
+	switch l {
+	case levelDebug:
+		return "debug"
+	case levelInfo:
+		return "info"
+	case levelWarn:
+		return "warn"
+	default:
+		return "error"
+	}
+}
+
+func parseLogLevel(s string) (logLevel, error) {
+	switch strings.ToLower(s) {
+	case "debug":
+		return levelDebug, nil
+	case "info", "":
+		return levelInfo, nil
+	case "warn", "warning":
+		return levelWarn, nil
+	case "error":
+		return levelError, nil
+	}
+	return levelInfo, fmt.Errorf("unknown log level %q", s)
+}
+
+// logs is the process-wide logger. main replaces it once flags are parsed.
+var logs = newLogger(os.Stderr, "logfmt", levelInfo)
+
+// logger is a small leveled logger that writes one structured line per event,
+// either as logfmt or JSON. Every line carries a timestamp, level and event
+// name, followed by the logger's context fields and the call's key/values.
+type logger struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	json   bool
+	level  logLevel
+	fields []interface{}
+}
+
+func newLogger(w io.Writer, format string, level logLevel) *logger {
+	return &logger{
+		mu:    &sync.Mutex{},
+		w:     w,
+		json:  format == "json",
+		level: level,
+	}
+}
+
+// With returns a logger that adds the key/value pairs to every line.
+func (l *logger) With(kv ...interface{}) *logger {
+	child := *l
+	child.fields = append(append([]interface{}{}, l.fields...), kv...)
+	return &child
+}
+
+func (l *logger) Debug(event string, kv ...interface{}) { l.log(levelDebug, event, kv) }
+func (l *logger) Info(event string, kv ...interface{})  { l.log(levelInfo, event, kv) }
+func (l *logger) Warn(event string, kv ...interface{})  { l.log(levelWarn, event, kv) }
+func (l *logger) Error(event string, kv ...interface{}) { l.log(levelError, event, kv) }
+
+// Fatal logs at error level and exits the process.
+func (l *logger) Fatal(event string, kv ...interface{}) {
+	l.log(levelError, event, kv)
+	os.Exit(1)
+}
+
+func (l *logger) log(level logLevel, event string, kv []interface{}) {
+	if level < l.level {
+		return
+	}
+
+	pairs := append([]interface{}{
+		"time", time.Now().UTC().Format(time.RFC3339Nano),
+		"level", level.String(),
+		"event", event,
+	}, l.fields...)
+	pairs = append(pairs, kv...)
+	if len(pairs)%2 != 0 {
+		pairs = append(pairs, "(MISSING)")
+	}
+
+	var line string
+	if l.json {
+		line = encodeJSON(pairs)
+	} else {
+		line = encodeLogfmt(pairs)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	io.WriteString(l.w, line+"\n")
+}
+
+func encodeLogfmt(pairs []interface{}) string {
+	var b strings.Builder
+	for i := 0; i < len(pairs); i += 2 {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(fmt.Sprint(pairs[i]))
+		b.WriteByte('=')
+
+		val := formatValue(pairs[i+1])
+		if val == "" || strings.ContainsAny(val, " =\"\t\n") {
+			val = fmt.Sprintf("%q", val)
+		}
+		b.WriteString(val)
+	}
+	return b.String()
+}
+
+func encodeJSON(pairs []interface{}) string {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i := 0; i < len(pairs); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(fmt.Sprint(pairs[i]))
+		val, err := json.Marshal(pairs[i+1])
+		if err != nil || isError(pairs[i+1]) {
+			val, _ = json.Marshal(formatValue(pairs[i+1]))
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(val)
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case error:
+		return v.Error()
+	case time.Duration:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func isError(v interface{}) bool {
+	_, ok := v.(error)
+	return ok
+}
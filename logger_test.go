@@ -0,0 +1,30 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerLogfmt(t *testing.T) {
+	var buf bytes.Buffer
+	l := newLogger(&buf, "logfmt", levelInfo).With("player", "bob|1.2.3.4")
+
+	l.Debug("ignored")
+	l.Info("guess_rejected", "reason", "invalid word", "err", fmt.Errorf("boom"))
+
+	line := buf.String()
+	assert.Contains(t, line, `level=info event=guess_rejected player=bob|1.2.3.4 reason="invalid word" err=boom`)
+	assert.NotContains(t, line, "ignored")
+}
+
+func TestLoggerJSON(t *testing.T) {
+	var buf bytes.Buffer
+	l := newLogger(&buf, "json", levelDebug)
+
+	l.Warn("read_line_failed", "guesses", 3, "err", fmt.Errorf("eof"))
+
+	assert.Contains(t, buf.String(), `"level":"warn","event":"read_line_failed","guesses":3,"err":"eof"}`)
+}
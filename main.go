@@ -1,29 +1,207 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
-	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gliderlabs/ssh"
 )
 
 func main() {
 	var (
-		dbFile  = flag.String("db", "wordle.db", "sqlite db file")
-		hostKey = flag.String("key", "key.pem", "key")
-		port    = flag.String("port", "22", "port")
+		configFile = flag.String("config", os.Getenv("WORDLE_CONFIG"), "YAML config file")
+		dbFile     = flag.String("db", "wordle.db", "sqlite db file")
+		hostKey    = flag.String("key", "key.pem", "host key file, generated as ed25519 if missing")
+		port       = flag.String("port", "22", "port")
+		httpAddr   = flag.String("http", "", "address for the metrics HTTP listener, e.g. :9090 (disabled if empty)")
+		logFormat  = flag.String("log-format", "logfmt", "log format: logfmt or json")
+		logLevel   = flag.String("log-level", "info", "log level: debug, info, warn or error")
+		audit      = flag.Bool("audit", false, "record connects, guesses and admin actions in the audit table")
 	)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [flags] [keygen | ban | export | import | backup | rebuild-stats] [args]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
 	flag.Parse()
 
-	repo, err := newRepo(*dbFile)
+	// keygen needs no config, so it runs before any is loaded.
+	cmd := flag.Arg(0)
+	if cmd == "keygen" {
+		if err := runKeygen(flag.Args()[1:]); err != nil {
+			logs.Fatal("keygen_failed", "err", err)
+		}
+		return
+	}
+
+	cfg, err := loadConfig(*configFile)
 	if err != nil {
-		log.Fatal(err)
+		logs.Fatal("load_config_failed", "err", err)
 	}
 
-	server, err := newServer(repo, *hostKey, *port)
+	// Flags take precedence, but only when given explicitly.
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "db":
+			cfg.DB = *dbFile
+		case "key":
+			cfg.HostKeys = []string{*hostKey}
+		case "port":
+			cfg.Listen = ":" + *port
+		case "http":
+			cfg.HTTP = *httpAddr
+		case "log-format":
+			cfg.Log.Format = *logFormat
+		case "log-level":
+			cfg.Log.Level = *logLevel
+		case "audit":
+			cfg.Features.Audit = *audit
+		}
+	})
+
+	if err := cfg.Validate(); err != nil {
+		logs.Fatal("invalid_config", "err", err)
+	}
+
+	level, _ := parseLogLevel(cfg.Log.Level)
+	logs = newLogger(os.Stderr, cfg.Log.Format, level)
+
+	dicts, err := loadDictionaries(cfg)
 	if err != nil {
-		log.Fatal(err)
+		logs.Fatal("load_words_failed", "err", err)
 	}
+	setDictionaries(dicts)
 
-	fmt.Printf("listening on :%s\n", *port)
-	log.Fatal(server.ListenAndServe())
+	// The streak rules are needed as soon as the database is opened, to
+	// backfill statistics.
+	repo, err := newRepo(cfg.DB, cfg.Streaks)
+	if err != nil {
+		logs.Fatal("open_db_failed", "db", cfg.DB, "err", err)
+	}
+	repo.auditLog = cfg.Features.Audit
+
+	switch cmd {
+	case "":
+	case "ban":
+		err := runBan(context.Background(), repo, flag.Args()[1:])
+		repo.Close()
+		if err != nil {
+			logs.Fatal("ban_failed", "err", err)
+		}
+		return
+	case "export", "import":
+		run := runExport
+		if cmd == "import" {
+			run = runImport
+		}
+		err := run(context.Background(), repo, flag.Args()[1:])
+		repo.Close()
+		if err != nil {
+			logs.Fatal(cmd+"_failed", "err", err)
+		}
+		return
+	case "backup":
+		err := runBackup(context.Background(), repo, cfg.Backup, flag.Args()[1:])
+		repo.Close()
+		if err != nil {
+			logs.Fatal("backup_failed", "err", err)
+		}
+		return
+	case "rebuild-stats":
+		err := runRebuildStats(context.Background(), repo, flag.Args()[1:])
+		repo.Close()
+		if err != nil {
+			logs.Fatal("rebuild_stats_failed", "err", err)
+		}
+		return
+	default:
+		logs.Fatal("unknown_command", "command", cmd)
+	}
+
+	sessions := newSessionRegistry()
+	server, err := newServer(repo, sessions, cfg)
+	if err != nil {
+		logs.Fatal("create_server_failed", "err", err)
+	}
+
+	var metricsServer *http.Server
+	if cfg.HTTP != "" {
+		metricsServer = newMetricsServer(cfg.HTTP)
+		go func() {
+			logs.Info("metrics_listening", "addr", cfg.HTTP)
+			if err := metricsServer.ListenAndServe(); err != http.ErrServerClosed {
+				logs.Fatal("metrics_server_stopped", "err", err)
+			}
+		}()
+	}
+
+	logDictionaries(dicts, "words_loaded")
+
+	maintenance, stopMaintenance := context.WithCancel(context.Background())
+	maintenanceDone := make(chan struct{})
+	go func() {
+		defer close(maintenanceDone)
+		runMaintenance(maintenance, repo, cfg.Backup, cfg.Retention)
+	}()
+
+	go func() {
+		logs.Info("listening", "addr", server.Addr)
+		if err := server.ListenAndServe(); err != ssh.ErrServerClosed {
+			logs.Fatal("server_stopped", "err", err)
+		}
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	sig := <-signals
+	for sig == syscall.SIGHUP {
+		logs.Info("reloading_words")
+		reloadWords(cfg)
+		sig = <-signals
+	}
+	signal.Stop(signals)
+
+	logs.Info("shutting_down", "signal", sig.String(), "sessions", sessions.Len(), "timeout", cfg.ShutdownTimeout)
+	stopMaintenance()
+	<-maintenanceDone
+	shutdown(server, sessions, repo, cfg.ShutdownTimeout)
+	if metricsServer != nil {
+		metricsServer.Close()
+	}
+	logs.Info("shutdown_complete")
+}
+
+// shutdown stops accepting connections, saves the in-progress game of every
+// connected player and then tells them, and gives them up to timeout to
+// finish before closing the remaining sessions and the database.
+func shutdown(server *ssh.Server, sessions *sessionRegistry, repo *sqliteRepo, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Shutdown(ctx)
+	}()
+
+	sessions.SaveGamesForRestart(context.Background(), repo, timeout)
+
+	if <-done; ctx.Err() != nil {
+		logs.Warn("drain_timeout", "sessions", sessions.Len())
+		sessions.SaveGames(context.Background(), repo)
+		server.Close()
+
+		// Give handlers a moment to unwind before the database goes away.
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		sessions.Wait(ctx)
+	}
+
+	if err := repo.Close(); err != nil {
+		logs.Error("close_db_failed", "err", err)
+	}
 }
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	metricActiveSessions = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "wordle_active_sessions",
+		Help: "Number of currently connected SSH sessions.",
+	})
+
+	// Connections per minute is derived with rate(wordle_connections_total[1m]).
+	metricConnections = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "wordle_connections_total",
+		Help: "Total number of SSH sessions started.",
+	})
+
+	metricRejectedConnections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "wordle_connections_rejected_total",
+		Help: "Total number of connections refused by rate limits or bans, by reason.",
+	}, []string{"reason"})
+
+	metricGuesses = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "wordle_guesses_total",
+		Help: "Total number of guesses submitted, valid or not.",
+	})
+
+	metricRejectedGuesses = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "wordle_guesses_rejected_total",
+		Help: "Total number of guesses rejected, by reason.",
+	}, []string{"reason"})
+
+	metricGamesFinished = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "wordle_games_finished_total",
+		Help: "Total number of games finished, by result.",
+	}, []string{"result"})
+
+	metricGuessCount = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "wordle_game_guesses",
+		Help:    "Number of guesses taken to win a game.",
+		Buckets: prometheus.LinearBuckets(1, 1, MaxGuesses),
+	})
+
+	metricRepoLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "wordle_repo_duration_seconds",
+		Help:    "Latency of repository operations.",
+		Buckets: prometheus.ExponentialBuckets(0.0005, 2, 12),
+	}, []string{"op"})
+)
+
+// newMetricsServer returns an HTTP server exposing Prometheus metrics on /metrics.
+func newMetricsServer(addr string) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	return &http.Server{
+		Addr:    addr,
+		Handler: mux,
+	}
+}
+
+// observeRepo records the latency of a repository operation started at start.
+// It is meant to be deferred: defer observeRepo("save_game", time.Now())
+func observeRepo(op string, start time.Time) {
+	metricRepoLatency.WithLabelValues(op).Observe(time.Since(start).Seconds())
+}
+
+// observeGameFinished records the result of a completed game.
+func observeGameFinished(game *Game) {
+	if game.Won {
+		metricGamesFinished.WithLabelValues("won").Inc()
+		metricGuessCount.Observe(float64(len(game.Guesses)))
+		return
+	}
+	metricGamesFinished.WithLabelValues("lost").Inc()
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserveGameFinished(t *testing.T) {
+	var (
+		won     = testutil.ToFloat64(metricGamesFinished.WithLabelValues("won"))
+		lost    = testutil.ToFloat64(metricGamesFinished.WithLabelValues("lost"))
+		winning = NewGame("water")
+		losing  = NewGame("water")
+	)
+	winning.Guesses, winning.Won = []string{"tower", "water"}, true
+	losing.Guesses = []string{"tower", "tower", "tower", "tower", "tower", "tower"}
+
+	observeGameFinished(winning)
+	observeGameFinished(losing)
+	observeGameFinished(losing)
+	assert.Equal(t, won+1, testutil.ToFloat64(metricGamesFinished.WithLabelValues("won")))
+	assert.Equal(t, lost+2, testutil.ToFloat64(metricGamesFinished.WithLabelValues("lost")))
+}
+
+func TestMetricsServer(t *testing.T) {
+	observeRepo("test_op", time.Now())
+	metricGuesses.Inc()
+
+	rec := httptest.NewRecorder()
+	newMetricsServer(":0").Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	assert.Contains(t, body, "wordle_guesses_total")
+	assert.Contains(t, body, `wordle_repo_duration_seconds_count{op="test_op"} 1`)
+
+	// Only /metrics is served.
+	rec = httptest.NewRecorder()
+	newMetricsServer(":0").Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
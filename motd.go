@@ -0,0 +1,61 @@
+package main
+
+import (
+	"io"
+	"strings"
+)
+
+// showMOTD shows the message of the day and any current announcement
+// before the board, waiting for the player to read them.
+func showMOTD(v *view, motd, announcement string) error {
+	var lines []string
+	if motd = strings.TrimRight(motd, "\n"); motd != "" {
+		lines = append(lines, strings.Split(motd, "\n")...)
+	}
+	if announcement != "" {
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, announcement)
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	if v.accessible {
+		print(v, strings.Join(lines, "\n")+"\n")
+		return nil
+	}
+
+	if !v.tui {
+		// The board clears the screen, so wait until they have read it.
+		print(v, strings.Join(lines, "\n")+"\n\n"+v.t("press_enter")+"\n")
+		_, err := v.readLine()
+		return err
+	}
+
+	v.draw(func() {
+		clear(v.s)
+		v.writeBlock(append(lines, "", v.t("press_key")))
+	})
+	key, err := readKey(v.keys)
+	if err != nil {
+		return err
+	}
+	if key == keyCtrlC || key == keyCtrlD {
+		return io.EOF
+	}
+	return nil
+}
+
+// notice shows an announcement without disturbing the game: on the message
+// line under the board in TUI mode, and above the prompt otherwise.
+func (v *view) notice(text string) {
+	v.flash(func() {
+		if v.tui {
+			v.showMessage(v.theme.SuccessText(text))
+			return
+		}
+		io.WriteString(v.term, text+"\n")
+	})
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShowMOTD(t *testing.T) {
+	newTUI := func(keys string) (*view, *fakeSession) {
+		s := &fakeSession{}
+		return &view{s: s, tui: true, keys: bufio.NewReader(strings.NewReader(keys)), theme: classicTheme, locale: "de"}, s
+	}
+
+	// Nothing to show doesn't wait for a key.
+	v, s := newTUI("")
+	require.NoError(t, showMOTD(v, "\n", ""))
+	assert.Empty(t, s.out.String())
+
+	// The board waits until the player has read them.
+	v, s = newTUI("x")
+	require.NoError(t, showMOTD(v, "Welcome!\nBe nice.\n", "Restart at noon"))
+	out := ansiEscape.ReplaceAllString(s.out.String(), "")
+	assert.Contains(t, out, "Welcome!\nBe nice.\n\nRestart at noon\n\nDrücke eine Taste zum Spielen\n")
+	v, _ = newTUI("\x03")
+	assert.Equal(t, io.EOF, showMOTD(v, "Welcome!", ""))
+
+	// Screen readers hear them without having to dismiss anything.
+	s = &fakeSession{}
+	v = &view{s: s, accessible: true, theme: classicTheme, locale: defaultLocale}
+	require.NoError(t, showMOTD(v, "Welcome!", "Restart at noon"))
+	assert.Equal(t, "Welcome!\n\nRestart at noon\n", s.out.String())
+}
+
+func TestBroadcast(t *testing.T) {
+	repo, err := newRepo(filepath.Join(t.TempDir(), "wordle.db"), StreakConfig{})
+	require.NoError(t, err)
+	defer repo.Close()
+
+	var (
+		ctx      = context.Background()
+		sessions = newSessionRegistry()
+		admin    = newAdminConsole(repo, sessions, nil)
+		notices  []string
+	)
+	for _, id := range []string{"a", "b"} {
+		_, err := sessions.Add(&liveSession{ID: id, notify: func(text string) { notices = append(notices, text) }}, 0)
+		require.NoError(t, err)
+	}
+	v := &view{s: &fakeSession{}, locale: defaultLocale}
+
+	// Everyone connected sees a broadcast, and with -for so do players who
+	// connect later.
+	assert.Equal(t, 0, admin.broadcastCommand(ctx, v, "root", []string{"restart", "at", "noon"}))
+	assert.Equal(t, []string{"restart at noon", "restart at noon"}, notices)
+	assert.Equal(t, "", sessions.Announcement())
+
+	assert.Equal(t, 0, admin.broadcastCommand(ctx, v, "root", []string{"-for", "1h", "back", "soon"}))
+	assert.Len(t, notices, 4)
+	assert.Equal(t, "back soon", sessions.Announcement())
+
+	assert.Equal(t, 2, admin.broadcastCommand(ctx, v, "root", nil))
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// PuzzleStats summarizes how every player on the server did on one day's
+// puzzle in one language. SaveGame keeps it up to date as games finish.
+// Games imported from elsewhere are not counted.
+type PuzzleStats struct {
+	Answer string
+	Played int
+	Wins   int
+	// Distribution counts wins by the number of guesses they took.
+	Distribution [MaxGuesses]int
+}
+
+func (s *PuzzleStats) add(game Game) {
+	s.Answer = game.Answer
+	s.Played++
+	if game.Won {
+		s.Wins++
+		s.Distribution[len(game.Guesses)-1]++
+	}
+}
+
+// newPuzzleStats counts the finished games of one puzzle.
+func newPuzzleStats(games Games) PuzzleStats {
+	var stats PuzzleStats
+	for _, game := range games.finished() {
+		stats.add(game)
+	}
+	return stats
+}
+
+// record returns the puzzle's games as a Record.
+func (s PuzzleStats) record() Record {
+	r := Record{Played: s.Played, Won: s.Wins}
+	for i, n := range s.Distribution {
+		r.Guesses += (i + 1) * n
+	}
+	return r
+}
+
+// WinPercent returns the share of games won, rounded down.
+func (s PuzzleStats) WinPercent() int {
+	return s.record().WinPercent()
+}
+
+// AverageGuesses returns the mean number of guesses the wins took.
+func (s PuzzleStats) AverageGuesses() float64 {
+	return s.record().AverageGuesses()
+}
+
+// Share returns the percentage of players who won in guesses guesses.
+func (s PuzzleStats) Share(guesses int) int {
+	if s.Played == 0 {
+		return 0
+	}
+	return s.Distribution[guesses-1] * 100 / s.Played
+}
+
+// Percentile returns the percentage of the other players game did better
+// than, and false if nobody else has finished the puzzle. Fewer guesses are
+// better, and any win is better than a loss.
+func (s PuzzleStats) Percentile(game Game) (int, bool) {
+	others := s.Played - 1
+	if others <= 0 {
+		return 0, false
+	}
+	if !game.Won {
+		return 0, true
+	}
+
+	worse := s.Played - s.Wins
+	for i := len(game.Guesses); i < MaxGuesses; i++ {
+		worse += s.Distribution[i]
+	}
+	return worse * 100 / others, true
+}
+
+// PuzzleStats returns how everyone did on the puzzle game is for.
+func (r *sqliteRepo) PuzzleStats(ctx context.Context, game *Game) (PuzzleStats, error) {
+	defer observeRepo("puzzle_stats", time.Now())
+
+	return puzzleStats(ctx, r.DB, langOf(*game), puzzleNumber(game.Started))
+}
+
+func puzzleStats(ctx context.Context, q querier, lang string, puzzle int) (PuzzleStats, error) {
+	var (
+		stats PuzzleStats
+		data  []byte
+	)
+	err := q.QueryRowContext(ctx, `SELECT data FROM puzzle_stats WHERE lang=? AND puzzle=?`, lang, puzzle).Scan(&data)
+	switch {
+	case err == sql.ErrNoRows:
+		return stats, nil
+	case err != nil:
+		return stats, err
+	}
+
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return stats, fmt.Errorf("failed to decode puzzle stats")
+	}
+	return stats, nil
+}
+
+func savePuzzleStats(ctx context.Context, q querier, lang string, puzzle int, stats PuzzleStats) error {
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return err
+	}
+
+	const upsert = `INSERT INTO puzzle_stats(lang, puzzle, data) VALUES(?, ?, ?)
+		ON CONFLICT(lang, puzzle) DO UPDATE SET data=excluded.data, updated_at=CURRENT_TIMESTAMP`
+	_, err = q.ExecContext(ctx, upsert, lang, puzzle, data)
+	return err
+}
+
+// countPuzzleGame adds a game that has just finished to its puzzle's
+// statistics.
+func countPuzzleGame(ctx context.Context, q querier, game *Game) error {
+	if game.Imported {
+		return nil
+	}
+	lang, puzzle := langOf(*game), puzzleNumber(game.Started)
+	stats, err := puzzleStats(ctx, q, lang, puzzle)
+	if err != nil {
+		return err
+	}
+	stats.add(*game)
+	return savePuzzleStats(ctx, q, lang, puzzle, stats)
+}
+
+// rebuildPuzzleStats recomputes the statistics of game's puzzle from every
+// player's games with the same answer.
+func rebuildPuzzleStats(ctx context.Context, q querier, game Game) error {
+	lang, puzzle := langOf(game), puzzleNumber(game.Started)
+	games, err := gamesWithAnswer(ctx, q, game.Answer)
+	if err != nil {
+		return err
+	}
+
+	stats := newPuzzleStats(games.split(puzzleKey)[puzzleID(lang, puzzle)])
+	if stats.Played == 0 {
+		_, err := q.ExecContext(ctx, `DELETE FROM puzzle_stats WHERE lang=? AND puzzle=?`, lang, puzzle)
+		return err
+	}
+	return savePuzzleStats(ctx, q, lang, puzzle, stats)
+}
+
+// rebuildAllPuzzleStats recomputes the statistics of every puzzle.
+func rebuildAllPuzzleStats(ctx context.Context, q querier) error {
+	games, err := gamesWithAnswer(ctx, q, "")
+	if err != nil {
+		return err
+	}
+
+	if _, err := q.ExecContext(ctx, `DELETE FROM puzzle_stats`); err != nil {
+		return err
+	}
+	for _, puzzle := range games.split(puzzleKey) {
+		g := puzzle[0]
+		if err := savePuzzleStats(ctx, q, langOf(g), puzzleNumber(g.Started), newPuzzleStats(puzzle)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// puzzleKey groups games by the puzzle they are for, leaving out imported
+// games.
+func puzzleKey(game Game) string {
+	if game.Imported {
+		return ""
+	}
+	return puzzleID(langOf(game), puzzleNumber(game.Started))
+}
+
+func puzzleID(lang string, puzzle int) string {
+	return fmt.Sprintf("%s/%d", lang, puzzle)
+}
+
+// gamesWithAnswer returns every player's games with answer, or all games if
+// answer is empty.
+func gamesWithAnswer(ctx context.Context, q querier, answer string) (Games, error) {
+	query, args := `SELECT data FROM game`, []interface{}{}
+	if answer != "" {
+		// The SQLite build has no JSON functions, so look for the answer as
+		// encoding/json writes it and check each match once decoded.
+		field, err := json.Marshal(struct{ Answer string }{answer})
+		if err != nil {
+			return nil, err
+		}
+		query, args = `SELECT data FROM game WHERE instr(data, ?) > 0`, []interface{}{string(field[1 : len(field)-1])}
+	}
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var games Games
+	for rows.Next() {
+		var (
+			data []byte
+			game Game
+		)
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &game); err != nil {
+			return nil, fmt.Errorf("failed to decode game")
+		}
+		if answer == "" || game.Answer == answer {
+			games = append(games, game)
+		}
+	}
+	return games, rows.Err()
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPuzzleStatsPercentile(t *testing.T) {
+	stats := PuzzleStats{Played: 5, Wins: 4, Distribution: [MaxGuesses]int{0, 1, 2, 1, 0, 0}}
+	var tests = []struct {
+		Name       string
+		Game       Game
+		Percentile int
+	}{
+		{"best", Game{Won: true, Guesses: []string{"crane", "ultra"}}, 100},
+		{"middle", Game{Won: true, Guesses: []string{"crane", "slate", "ultra"}}, 50},
+		{"worst win", Game{Won: true, Guesses: []string{"crane", "slate", "moist", "ultra"}}, 25},
+		{"lost", Game{Guesses: []string{"crane"}}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			percentile, ok := stats.Percentile(tt.Game)
+			assert.True(t, ok)
+			assert.Equal(t, tt.Percentile, percentile)
+		})
+	}
+
+	_, ok := PuzzleStats{Played: 1, Wins: 1}.Percentile(Game{Won: true, Guesses: []string{"ultra"}})
+	assert.False(t, ok)
+	assert.Equal(t, 40, stats.Share(3))
+	assert.Equal(t, 3.0, stats.AverageGuesses())
+}
+
+func TestPuzzleStatsKeptBySaveGame(t *testing.T) {
+	ctx := context.Background()
+	repo, err := newRepo(filepath.Join(t.TempDir(), "wordle.db"), StreakConfig{})
+	require.NoError(t, err)
+	defer repo.Close()
+
+	day := time.Date(2026, time.October, 1, 12, 0, 0, 0, time.UTC)
+	play := func(user string, guesses ...string) *Game {
+		game := NewGame("ultra")
+		game.Started = day
+		require.NoError(t, repo.SaveGame(ctx, user, game))
+		for _, guess := range guesses {
+			game.Guess(guess)
+			require.NoError(t, repo.SaveGame(ctx, user, game))
+		}
+		return game
+	}
+
+	play("alice", "crane", "ultra")
+	bob := play("bob", "ultra")
+	game := play("carol", "crane", "slate")
+
+	stats, err := repo.PuzzleStats(ctx, game)
+	require.NoError(t, err)
+	assert.Equal(t, PuzzleStats{Answer: "ultra", Played: 2, Wins: 2, Distribution: [MaxGuesses]int{1, 1, 0, 0, 0, 0}}, stats)
+
+	require.NoError(t, repo.DeleteGame(ctx, bob.ID))
+	stats, err = repo.PuzzleStats(ctx, game)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.Played)
+
+	_, err = repo.DB.Exec(`DELETE FROM puzzle_stats`)
+	require.NoError(t, err)
+	_, err = repo.RebuildStats(ctx, "")
+	require.NoError(t, err)
+	stats, err = repo.PuzzleStats(ctx, game)
+	require.NoError(t, err)
+	assert.Equal(t, PuzzleStats{Answer: "ultra", Played: 1, Wins: 1, Distribution: [MaxGuesses]int{0, 1, 0, 0, 0, 0}}, stats)
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// replayShortcut is typed on the statistics screen to watch the game just
+// played again; `ssh host replay <puzzle>` replays any finished game.
+const replayShortcut = "/replay"
+
+// maxReplayPause caps the wait between two moves of a replay, so that long
+// thinks and time away don't stall it.
+const maxReplayPause = 2 * time.Second
+
+// replayStep is a move of a replay and how long to wait before showing it.
+type replayStep struct {
+	Event
+	Pause time.Duration
+	// Elapsed is how far into the game the move was made.
+	Elapsed time.Duration
+}
+
+// replaySteps returns the moves of a finished game with their original
+// timing. Games from before events were logged replay their guesses a
+// second apart.
+func replaySteps(game Game) []replayStep {
+	var steps []replayStep
+	if len(game.Events) == 0 {
+		for i, word := range game.Guesses {
+			steps = append(steps, replayStep{Event: Event{Kind: eventGuess, Word: word}, Pause: time.Second, Elapsed: time.Duration(i+1) * time.Second})
+		}
+		return steps
+	}
+
+	last := game.Started
+	for _, ev := range game.Events {
+		pause := ev.At.Sub(last)
+		switch {
+		case pause > maxReplayPause:
+			pause = maxReplayPause
+		case pause < 0:
+			pause = 0
+		}
+		steps = append(steps, replayStep{Event: ev, Pause: pause, Elapsed: ev.At.Sub(game.Started)})
+		last = ev.At
+	}
+	return steps
+}
+
+// describeStep says what happened in a move other than an accepted guess.
+func describeStep(v *view, step replayStep) string {
+	switch step.Kind {
+	case eventReject:
+		switch step.Reason {
+		case rejectReason(ErrWordLength):
+			return v.t("word_length", WordLength)
+		case rejectReason(ErrInvalidWord):
+			return v.t("invalid_word", step.Word)
+		case rejectReason(ErrTooManyGuesses):
+			return v.t("slow_down")
+		}
+		return step.Word
+	case eventHint:
+		return v.t("hint_used")
+	case eventDisconnect:
+		return v.t("left_game")
+	case eventResume:
+		return v.t("came_back")
+	}
+	return ""
+}
+
+// showReplay plays a finished game back move by move: in TUI mode on the
+// board, with each guess flipping over as it did, and otherwise in words.
+func showReplay(v *view, game Game) {
+	shown := Game{Answer: game.Answer, Lang: game.Lang, Started: game.Started}
+	if !v.tui {
+		print(v, v.t("replay_of", puzzleNumber(game.Started))+"\n")
+	} else {
+		v.draw(func() {
+			v.pending = ""
+			clear(v.s)
+			drawBoard(v, &shown)
+		})
+	}
+
+	for _, step := range replaySteps(game) {
+		time.Sleep(step.Pause)
+		stamp := formatElapsed(step.Elapsed)
+
+		if !v.tui {
+			text := describeStep(v, step)
+			if step.Kind == eventGuess {
+				shown.Guesses = append(shown.Guesses, step.Word)
+				text = describeGuess(v, &shown, len(shown.Guesses)-1)
+			}
+			print(v, stamp+" "+text+"\n")
+			continue
+		}
+
+		switch step.Kind {
+		case eventGuess:
+			shown.Guesses = append(shown.Guesses, step.Word)
+			reveal(v, &shown)
+			v.flash(func() { v.showMessage(stamp) })
+		case eventReject:
+			v.flash(func() { v.pending = step.Word })
+			reject(v, &shown, stamp+" "+describeStep(v, step))
+		default:
+			v.flash(func() { v.showMessage(stamp + " " + describeStep(v, step)) })
+		}
+	}
+	time.Sleep(maxReplayPause)
+}
+
+// offerReplay waits on the statistics screen of a finished game, replaying
+// it each time the player types /replay, or another group member's game of
+// the same puzzle for /replay <player>, until they press any other key.
+func offerReplay(ctx context.Context, v *view, repo *sqliteRepo, user string, game *Game) error {
+	if !v.tui || game.Imported {
+		return nil
+	}
+
+	for {
+		key, err := readKey(v.keys)
+		if err != nil {
+			return err
+		}
+		if key != '/' {
+			return nil
+		}
+		v.mu.Lock()
+		stats := v.last
+		v.mu.Unlock()
+
+		// The shortcut is typed over the statistics, so draw them again
+		// whatever it was.
+		command, err := readShortcut(v)
+		if err != nil {
+			return err
+		}
+		if fields := strings.Fields(command); len(fields) > 0 && fields[0] == replayShortcut {
+			replay := *game
+			if len(fields) > 1 {
+				replay, err = memberReplay(ctx, v, repo, user, fields[1], game)
+			}
+			if err != nil {
+				v.flash(func() { v.showMessage(v.theme.ErrorText(err.Error())) })
+				time.Sleep(maxReplayPause)
+			} else {
+				showReplay(v, replay)
+			}
+		}
+		v.draw(stats)
+	}
+}
+
+// memberReplay returns the game player, in user's group, played of the
+// same puzzle as game, which user has finished.
+func memberReplay(ctx context.Context, v *view, repo *sqliteRepo, user, player string, game *Game) (Game, error) {
+	puzzle := puzzleNumber(game.Started)
+	if game.Clock == nil {
+		return findReplay(ctx, v, repo, user, player, puzzle)
+	}
+
+	owner, err := groupMember(ctx, v, repo, user, player)
+	if err != nil {
+		return Game{}, err
+	}
+	run, err := repo.Speedrun(ctx, owner, langOf(*game), puzzle)
+	if err != nil {
+		logs.Error("speedrun_failed", "player", owner, "err", err)
+		return Game{}, errors.New(v.t("load_games_failed"))
+	}
+	if run == nil || !run.IsDone() {
+		return Game{}, errors.New(v.t("member_not_finished_puzzle", player, puzzle))
+	}
+	return *run, nil
+}
+
+// findReplay returns the finished game of puzzle, or the latest if puzzle is
+// negative, that user played, or that player in user's group did if player
+// is set. Others' games of today's puzzle are only shown once user has
+// finished it too, so they don't give the answer away. Its errors are for
+// the player.
+func findReplay(ctx context.Context, v *view, repo *sqliteRepo, user, player string, puzzle int) (Game, error) {
+	lang := dictionaryFor(v.locale).Lang
+	owner := user
+	if player != "" {
+		var err error
+		if owner, err = groupMember(ctx, v, repo, user, player); err != nil {
+			return Game{}, err
+		}
+	}
+
+	games, err := repo.ListGames(ctx, owner)
+	if err != nil {
+		logs.Error("list_games_failed", "player", owner, "err", err)
+		return Game{}, errors.New(v.t("load_games_failed"))
+	}
+	game, found := latestFinished(games.InLang(lang), puzzle)
+
+	switch {
+	case !found && owner == user && puzzle < 0:
+		return Game{}, errors.New(v.t("no_games_yet"))
+	case !found && owner == user:
+		return Game{}, errors.New(v.t("not_finished_puzzle", puzzle))
+	case !found && puzzle < 0:
+		return Game{}, errors.New(v.t("member_no_games", player))
+	case !found:
+		return Game{}, errors.New(v.t("member_not_finished_puzzle", player, puzzle))
+	case game.Imported:
+		return Game{}, errors.New(v.t("imported_no_replay", puzzleNumber(game.Started)))
+	}
+
+	if today := puzzleNumber(time.Now()); owner != user && puzzleNumber(game.Started) >= today {
+		mine, err := repo.ListGames(ctx, user)
+		if err != nil {
+			logs.Error("list_games_failed", "player", user, "err", err)
+			return Game{}, errors.New(v.t("load_games_failed"))
+		}
+		if _, done := latestFinished(mine.InLang(lang), puzzleNumber(game.Started)); !done {
+			return Game{}, errors.New(v.t("finish_today_first"))
+		}
+	}
+	return game, nil
+}
+
+// latestFinished returns the latest finished game of puzzle in games, or of
+// any puzzle if puzzle is negative.
+func latestFinished(games Games, puzzle int) (Game, bool) {
+	// Games are newest first, so the first match is the latest.
+	for _, game := range games {
+		if game.IsDone() && (puzzle < 0 || puzzleNumber(game.Started) == puzzle) {
+			return game, true
+		}
+	}
+	return Game{}, false
+}
+
+// replayHint tells the player on the statistics screen how to replay the
+// game or leave.
+func replayHint(v *view) string {
+	if v.compact() {
+		return v.t("replay_hint_short")
+	}
+	return v.t("replay_hint")
+}
+
+// replayCommand replays one of the player's finished games, or a group
+// member's, e.g. `ssh host replay 1948` for puzzle 1948, `ssh host replay
+// 1948 bob` for bob's, or the latest without a puzzle.
+func replayCommand(ctx context.Context, v *view, repo *sqliteRepo, user string, args []string) int {
+	var (
+		puzzle = -1
+		player string
+	)
+	for _, arg := range args {
+		n, err := strconv.Atoi(strings.TrimPrefix(arg, "#"))
+		switch {
+		case err == nil && n >= 0 && puzzle < 0:
+			puzzle = n
+		case err != nil && player == "":
+			player = arg
+		default:
+			print(v, "usage: replay [puzzle] [player]\n")
+			return 2
+		}
+	}
+
+	settings, err := repo.GetSettings(ctx, user)
+	if err != nil {
+		logs.Error("get_settings_failed", "player", user, "err", err)
+	}
+	if settings.Accessible {
+		v.setAccessible()
+	}
+
+	game, err := findReplay(ctx, v, repo, user, player, puzzle)
+	if err != nil {
+		print(v, err.Error()+"\n")
+		return 1
+	}
+	showReplay(v, game)
+	return 0
+}
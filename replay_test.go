@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplaySteps(t *testing.T) {
+	game := NewGame("ultra")
+	game.Guess("crane")
+	game.Guess("ultra")
+	steps := replaySteps(*game)
+	require.Len(t, steps, 2)
+	assert.Equal(t, "crane", steps[0].Word)
+	assert.True(t, steps[1].Pause <= maxReplayPause)
+
+	// Time away is cut short, and events out of order don't wait at all.
+	start := game.Started
+	game.Events = []Event{
+		{Kind: eventGuess, At: start.Add(time.Second), Word: "crane"},
+		{Kind: eventDisconnect, At: start.Add(2 * time.Second)},
+		{Kind: eventResume, At: start.Add(time.Hour)},
+		{Kind: eventGuess, At: start.Add(time.Hour - time.Second), Word: "ultra"},
+	}
+	steps = replaySteps(*game)
+	var pauses []time.Duration
+	for _, step := range steps {
+		pauses = append(pauses, step.Pause)
+	}
+	assert.Equal(t, []time.Duration{time.Second, time.Second, maxReplayPause, 0}, pauses)
+	assert.Equal(t, time.Hour, steps[2].Elapsed)
+
+	// Games from before events were logged replay their guesses.
+	game.Events = nil
+	steps = replaySteps(*game)
+	require.Len(t, steps, 2)
+	assert.Equal(t, Event{Kind: eventGuess, Word: "ultra"}, steps[1].Event)
+	assert.Equal(t, time.Second, steps[1].Pause)
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+	"unicode/utf8"
+)
+
+// dateLayout is how days are written in the schedule. Days are in UTC, like
+// the word of the day.
+const dateLayout = "2006-01-02"
+
+// ScheduledAnswer overrides the answer in one language on one day.
+type ScheduledAnswer struct {
+	Date      string
+	Lang      string
+	Word      string
+	CreatedBy string
+	Created   time.Time
+}
+
+// todaysAnswer returns the answer on the day of now in dict's language: the
+// one an admin scheduled, if any, otherwise the next word of the list.
+func todaysAnswer(ctx context.Context, repo *sqliteRepo, dict *dictionary, now time.Time) string {
+	date := now.UTC().Format(dateLayout)
+	word, err := repo.ScheduledAnswerOn(ctx, date, dict.Lang)
+	switch {
+	case err != nil:
+		logs.Error("scheduled_answer_failed", "date", date, "lang", dict.Lang, "err", err)
+	case word != "" && !dict.IsAllowed(word):
+		// The word lists were replaced since it was scheduled; nobody could
+		// guess it.
+		logs.Warn("scheduled_answer_not_allowed", "date", date, "lang", dict.Lang)
+	case word != "":
+		return word
+	}
+	return dict.WordOfTheDay(now)
+}
+
+// scheduledWords returns the answers scheduled in lang from the day of now
+// on, which other puzzles must not give away.
+func scheduledWords(ctx context.Context, repo *sqliteRepo, lang string, now time.Time) []string {
+	entries, err := repo.ListSchedule(ctx, now.UTC().Format(dateLayout))
+	if err != nil {
+		logs.Error("list_schedule_failed", "err", err)
+		return nil
+	}
+	var words []string
+	for _, entry := range entries {
+		if entry.Lang == lang {
+			words = append(words, entry.Word)
+		}
+	}
+	return words
+}
+
+// parseFutureDate parses a schedule day, which must be after today so that
+// nobody's game changes answer halfway through.
+func parseFutureDate(s string, now time.Time) (string, error) {
+	date, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return "", fmt.Errorf("invalid date %q, want YYYY-MM-DD", s)
+	}
+	today := now.UTC().Format(dateLayout)
+	if day := date.Format(dateLayout); day > today {
+		return day, nil
+	}
+	return "", fmt.Errorf("only days after today (%s UTC) can be scheduled", today)
+}
+
+// scheduleCommand lists the scheduled answers or schedules one. Words are
+// hidden unless asked for, and never logged.
+func (a *adminConsole) scheduleCommand(ctx context.Context, v *view, user string, args []string) int {
+	var (
+		flags  = flag.NewFlagSet("schedule", flag.ContinueOnError)
+		lang   = flags.String("lang", defaultLocale, "language of the word game")
+		reveal = flags.Bool("reveal", false, "show the scheduled words")
+		now    = time.Now()
+	)
+	flags.SetOutput(viewWriter{v})
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	switch flags.NArg() {
+	case 0:
+		entries, err := a.repo.ListSchedule(ctx, now.UTC().Format(dateLayout))
+		if err != nil {
+			logs.Error("list_schedule_failed", "err", err)
+			print(v, "failed to load the schedule\n")
+			return 1
+		}
+		if len(entries) == 0 {
+			print(v, "no answers are scheduled\n")
+		}
+		for _, entry := range entries {
+			word := "*****"
+			if *reveal {
+				word = entry.Word
+			}
+			print(v, fmt.Sprintf("%s  %-3s %s  %s\n", entry.Date, entry.Lang, word, entry.CreatedBy))
+		}
+		return 0
+	case 2:
+	default:
+		print(v, adminUsage)
+		return 2
+	}
+
+	date, err := parseFutureDate(flags.Arg(0), now)
+	if err != nil {
+		print(v, err.Error()+"\n")
+		return 1
+	}
+	if *lang != defaultLocale && !hasDictionary(*lang) {
+		print(v, fmt.Sprintf("there is no word list for %q\n", *lang))
+		return 1
+	}
+	word := normalize(*lang, flags.Arg(1))
+	if utf8.RuneCountInString(word) != WordLength || !dictionaryFor(*lang).IsAllowed(word) {
+		print(v, fmt.Sprintf("%q is not in the %s word list\n", flags.Arg(1), *lang))
+		return 1
+	}
+
+	// Scheduling today's speedrun answer would change the speedrun halfway
+	// through the day.
+	if dict := dictionaryFor(*lang); word == speedrunWord(dict, now, scheduledWords(ctx, a.repo, *lang, now)) {
+		print(v, fmt.Sprintf("%q is today's speedrun answer, choose another word\n", flags.Arg(1)))
+		return 1
+	}
+
+	entry := ScheduledAnswer{Date: date, Lang: *lang, Word: word, CreatedBy: user}
+	if err := a.repo.ScheduleAnswer(ctx, entry); err != nil {
+		logs.Error("schedule_answer_failed", "err", err)
+		print(v, "failed to schedule the answer\n")
+		return 1
+	}
+	a.audit(ctx, user, auditSchedule, fmt.Sprintf("schedule %s %s", date, *lang))
+	logs.Info("answer_scheduled", "player", user, "date", date, "lang", *lang)
+
+	print(v, fmt.Sprintf("scheduled the %s answer for %s\n", *lang, date))
+	return 0
+}
+
+// unscheduleCommand removes a scheduled answer.
+func (a *adminConsole) unscheduleCommand(ctx context.Context, v *view, user string, args []string) int {
+	var (
+		flags = flag.NewFlagSet("unschedule", flag.ContinueOnError)
+		lang  = flags.String("lang", defaultLocale, "language of the word game")
+	)
+	flags.SetOutput(viewWriter{v})
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		print(v, adminUsage)
+		return 2
+	}
+
+	date, err := parseFutureDate(flags.Arg(0), time.Now())
+	if err != nil {
+		print(v, err.Error()+"\n")
+		return 1
+	}
+
+	removed, err := a.repo.UnscheduleAnswer(ctx, date, *lang)
+	if err != nil {
+		logs.Error("unschedule_answer_failed", "err", err)
+		print(v, "failed to remove the answer\n")
+		return 1
+	}
+	if !removed {
+		print(v, fmt.Sprintf("no %s answer is scheduled for %s\n", *lang, date))
+		return 1
+	}
+	a.audit(ctx, user, auditSchedule, fmt.Sprintf("unschedule %s %s", date, *lang))
+	logs.Info("answer_unscheduled", "player", user, "date", date, "lang", *lang)
+
+	print(v, fmt.Sprintf("removed the %s answer for %s\n", *lang, date))
+	return 0
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFutureDate(t *testing.T) {
+	now := time.Date(2026, 10, 31, 23, 0, 0, 0, time.UTC)
+
+	date, err := parseFutureDate("2026-11-01", now)
+	require.NoError(t, err)
+	assert.Equal(t, "2026-11-01", date)
+
+	for _, s := range []string{"2026-10-31", "2026-10-01", "11/01/2026", "2026-13-01"} {
+		_, err := parseFutureDate(s, now)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestTodaysAnswer(t *testing.T) {
+	repo, err := newRepo(filepath.Join(t.TempDir(), "wordle.db"), StreakConfig{})
+	require.NoError(t, err)
+	defer repo.Close()
+
+	var (
+		ctx  = context.Background()
+		dict = newDictionary(defaultLocale, []string{"crane", "slate"}, []string{"adieu"})
+		now  = time.Date(2026, 11, 1, 12, 0, 0, 0, time.UTC)
+	)
+	assert.Equal(t, dict.WordOfTheDay(now), todaysAnswer(ctx, repo, dict, now))
+
+	require.NoError(t, repo.ScheduleAnswer(ctx, ScheduledAnswer{Date: "2026-11-01", Lang: defaultLocale, Word: "adieu", CreatedBy: "admin"}))
+	assert.Equal(t, "adieu", todaysAnswer(ctx, repo, dict, now))
+	assert.Equal(t, dict.WordOfTheDay(now.AddDate(0, 0, 1)), todaysAnswer(ctx, repo, dict, now.AddDate(0, 0, 1)))
+
+	// A word that is no longer allowed is ignored.
+	require.NoError(t, repo.ScheduleAnswer(ctx, ScheduledAnswer{Date: "2026-11-01", Lang: defaultLocale, Word: "zzzzz", CreatedBy: "admin"}))
+	assert.Equal(t, dict.WordOfTheDay(now), todaysAnswer(ctx, repo, dict, now))
+
+	removed, err := repo.UnscheduleAnswer(ctx, "2026-11-01", defaultLocale)
+	require.NoError(t, err)
+	assert.True(t, removed)
+}
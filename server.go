@@ -1,208 +1,592 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
-	"log"
-	"os"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gliderlabs/ssh"
-	gossh "golang.org/x/crypto/ssh"
-	"golang.org/x/crypto/ssh/terminal"
 )
 
-func newServer(repo *sqliteRepo, hostKey, port string) (*ssh.Server, error) {
+func newServer(repo *sqliteRepo, sessions *sessionRegistry, cfg *Config) (*ssh.Server, error) {
+	guard := newAbuseGuard(repo, cfg.RateLimits)
 	server := &ssh.Server{
-		Addr:        fmt.Sprintf(":%s", port),
-		IdleTimeout: time.Minute * 5,
-		Handler:     newHandler(repo),
+		Addr:                       cfg.Listen,
+		IdleTimeout:                cfg.IdleTimeout,
+		Handler:                    newHandler(repo, sessions, guard, newAdminConsole(repo, sessions, cfg.Admins), cfg.MOTD, cfg.Modes),
+		ConnCallback:               guard.ConnCallback,
+		PublicKeyHandler:           guard.PublicKeyHandler,
+		KeyboardInteractiveHandler: guard.KeyboardInteractiveHandler,
 	}
 
-	hostKeyPEM, err := os.ReadFile(hostKey)
+	hostKeySigners, err := loadHostKeys(cfg.HostKeys)
 	if err != nil {
 		return nil, err
 	}
-	hostKeySigner, err := gossh.ParsePrivateKey(hostKeyPEM)
-	if err != nil {
-		return nil, err
+	for _, signer := range hostKeySigners {
+		server.AddHostKey(signer)
 	}
-	server.AddHostKey(hostKeySigner)
 
 	return server, nil
 }
 
-func newHandler(repo *sqliteRepo) func(ssh.Session) {
+func newHandler(repo *sqliteRepo, sessions *sessionRegistry, guard *abuseGuard, admin *adminConsole, motd string, modes []string) func(ssh.Session) {
 	return func(s ssh.Session) {
 		var (
 			ctx        = s.Context()
-			term       = terminal.NewTerminal(s, "")
+			v          = newView(s)
 			user       = userKey(s)
-			todaysWord = wordOfTheDay()
-			game       = NewGame(todaysWord)
+			todaysWord string
+			game       *Game
+			sessionID  = sessionIDOf(ctx)
+			remote     = s.RemoteAddr().String()
+			l          = logs.With("session", shortID(sessionID), "player", user, "remote", remote)
 		)
-		term.SetPrompt("> ")
+		defer v.close()
+		if fingerprint := fingerprintOf(ctx); fingerprint != "" {
+			l = l.With("key", fingerprint)
+		}
 
-		log.Printf("player connected: %s\n", user)
+		audit := func(event, detail string) {
+			ev := auditEvent{Event: event, Session: sessionID, User: user, Remote: remote, Detail: detail}
+			if err := repo.Audit(ctx, ev); err != nil {
+				l.Error("audit_failed", "audit_event", event, "err", err)
+			}
+		}
+		// Every session is registered, commands included, so that they
+		// count towards the player's cap and admins can see them.
+		ls := &liveSession{
+			ID:      sessionID,
+			User:    user,
+			Remote:  remote,
+			Key:     fingerprintOf(ctx),
+			Started: time.Now(),
+			sess:    s,
+			notify:  v.notice,
+		}
+		remove, err := guard.AdmitSession(ls, sessions)
+		if err != nil {
+			print(v, v.theme.ErrorText(err.Error())+"\n")
+			return
+		}
+		// Deferred first so it runs last: the session stays registered
+		// until its game is saved, which admin reset waits for.
+		defer remove()
+
+		settings, err := repo.GetSettings(ctx, user)
+		if err != nil {
+			l.Error("get_settings_failed", "err", err)
+		}
+		v.theme, _ = findTheme(settings.Theme)
+		if _, ok := catalogs[settings.Locale]; ok {
+			v.locale = settings.Locale
+		}
+		ls.SetLocale(v.locale)
+
+		// Players whose language has no word list play the English game
+		// with translated text.
+		dict := dictionaryFor(v.locale)
+		todaysWord = todaysAnswer(ctx, repo, dict, time.Now())
+		game = NewGame(todaysWord)
+		game.Lang = dict.Lang
+
+		args := s.Command()
+		if len(args) > 0 && args[0] != "play" && args[0] != speedrunMode {
+			l.Info("command", "command", args[0])
+			s.Exit(runCommand(ctx, v, repo, admin, user, args))
+			return
+		}
+
+		opts, err := parsePlayArgs(v, args)
+		if err != nil {
+			s.Exit(2)
+			return
+		}
+		if opts.accessible || settings.Accessible {
+			v.setAccessible()
+		}
+
+		mode := "daily"
+		if len(args) > 0 && args[0] == speedrunMode {
+			mode = speedrunMode
+			game = newSpeedrun(ctx, repo, dict, time.Now())
+		}
+		if !contains(modes, mode) {
+			print(v, v.t("mode_disabled", mode)+"\n")
+			s.Exit(1)
+			return
+		}
+		save := repo.SaveGame
+		if mode == speedrunMode {
+			save = repo.SaveSpeedrun
+		}
+
+		saveGame := func() {
+			ls.mu.Lock()
+			defer ls.mu.Unlock()
+			if err := save(ctx, user, game); err != nil {
+				l.Error("save_game_failed", "game", game.ID, "err", err)
+			}
+		}
+		// Leaving a game partway through is logged on it too. The session's
+		// context is done by then, so save without it.
 		defer func() {
-			log.Printf("player disconnected: %s\n", user)
+			ls.mu.Lock()
+			defer ls.mu.Unlock()
+			if game.IsDone() || game.ID == 0 && len(game.Events) == 0 {
+				return
+			}
+			game.record(eventDisconnect, "", "")
+			if err := save(context.Background(), user, game); err != nil {
+				l.Error("save_game_failed", "game", game.ID, "err", err)
+			}
 		}()
 
-		games, err := repo.ListGames(ctx, user)
-		if err != nil {
-			log.Printf("failed to list games for user %s: %v", user, err.Error())
+		l.Info("player_connected")
+		audit(auditConnect, "")
+		metricConnections.Inc()
+		metricActiveSessions.Inc()
+		defer func() {
+			l.Info("player_disconnected")
+			audit(auditDisconnect, "")
+			metricActiveSessions.Dec()
+		}()
+
+		if err := showMOTD(v, motd, sessions.Announcement()); err != nil {
 			return
 		}
 
-		if len(games) > 0 {
-			lastGame := games[0]
-			if lastGame.Answer == todaysWord {
-				if lastGame.IsDone() {
-					// Today's game is already complete
-					renderStats(s, term, &lastGame, games)
-					return
-				} else {
-					// Continue the unfinished game
-					game = &lastGame
+		// showStats shows the player's statistics, and everyone's for
+		// today's puzzle, once their game is saved. Speedruns show their
+		// time and the day's fastest instead. The player can then replay
+		// the game.
+		showStats := func(game *Game) {
+			if game.Clock != nil {
+				fastest, err := repo.FastestSpeedruns(ctx, game.Lang, puzzleNumber(game.Started), leaderboardSize)
+				if err != nil {
+					l.Error("fastest_speedruns_failed", "err", err)
+				}
+				renderSpeedrun(v, game, fastest)
+			} else {
+				stats, err := repo.PlayerStats(ctx, user, langOf(*game))
+				if err != nil {
+					l.Error("player_stats_failed", "err", err)
+				}
+				today, err := repo.PuzzleStats(ctx, game)
+				if err != nil {
+					l.Error("puzzle_stats_failed", "err", err)
+				}
+				renderStats(v, game, stats, today)
+			}
+
+			if err := offerReplay(ctx, v, repo, user, game); err != nil && err != io.EOF {
+				l.Warn("read_line_failed", "err", err)
+			}
+		}
+
+		if mode == speedrunMode {
+			// Only today's speedrun can be resumed, with its clock still
+			// running.
+			resumed, err := repo.Speedrun(ctx, user, game.Lang, puzzleNumber(game.Started))
+			switch {
+			case err != nil:
+				l.Error("speedrun_failed", "err", err)
+				return
+			case resumed != nil && resumed.IsDone():
+				showStats(resumed)
+				return
+			case resumed != nil:
+				game = resumed
+				game.record(eventResume, "", "")
+				l.Debug("speedrun_resumed", "game", game.ID, "guesses", len(game.Guesses))
+			}
+		} else {
+			games, err := repo.ListGames(ctx, user)
+			if err != nil {
+				l.Error("list_games_failed", "err", err)
+				return
+			}
+			games = games.InLang(game.Lang)
+
+			if len(games) > 0 {
+				lastGame := games[0]
+				if lastGame.Answer == todaysWord {
+					if lastGame.IsDone() {
+						// Today's game is already complete
+						showStats(&lastGame)
+						return
+					} else {
+						// Continue the unfinished game
+						game = &lastGame
+						game.record(eventResume, "", "")
+						l.Debug("game_resumed", "game", game.ID, "guesses", len(game.Guesses))
+					}
 				}
 			}
 		}
 
+		ls.SetGame(game)
+
 		// Render the initial game board
-		render(s, term, game)
+		render(v, game)
+		if game.Clock != nil && game.Clock.Start.IsZero() {
+			if err := startClock(v, game); err != nil {
+				return
+			}
+			saveGame()
+		}
 
 		for {
-			word, err := term.ReadLine()
+			word, err := readGuess(v, game)
 			if err != nil {
-				log.Printf("read line err: %v", err)
+				if err != io.EOF {
+					l.Warn("read_line_failed", "err", err)
+				}
 				return
 			}
 
+			if strings.TrimSpace(word) == summaryCommand {
+				ls.mu.Lock()
+				game.record(eventHint, "", "")
+				ls.mu.Unlock()
+				print(v, summarize(v, game)+"\n")
+				continue
+			}
+			if strings.TrimSpace(word) == calendarShortcut {
+				if err := showCalendar(ctx, v, repo, user, game.Lang, time.Now()); err != nil {
+					if err != io.EOF {
+						l.Error("list_games_failed", "err", err)
+					}
+					return
+				}
+				render(v, game)
+				continue
+			}
+			if strings.HasPrefix(word, "/") {
+				reject(v, game, v.t("unknown_shortcut", strings.TrimSpace(word), calendarShortcut))
+				continue
+			}
+
+			if err := guard.AllowGuess(user); err != nil {
+				l.Warn("guess_rate_limited")
+				ls.mu.Lock()
+				game.record(eventReject, normalize(game.Lang, word), rejectReason(err))
+				ls.mu.Unlock()
+				reject(v, game, v.errorText(err, word))
+				continue
+			}
+
+			ls.mu.Lock()
 			err, win := game.Guess(word)
+			ls.mu.Unlock()
+			metricGuesses.Inc()
+			audit(auditGuess, word)
 			switch {
 			case win:
 				// Win, game over
-				render(s, term, game)
+				observeGameFinished(game)
+				l.Info("game_won", "guesses", len(game.Guesses))
+				reveal(v, game)
 				time.Sleep(time.Millisecond * 700)
-				warnGreen(s, term, "Winner!\n")
-				repo.SaveGame(ctx, user, game)
-				renderStats(s, term, game, games)
+				warnGreen(v, v.t("winner")+"\n")
+				saveGame()
+				showStats(game)
 				return
 			case err != nil && errors.Is(err, ErrGameOver):
 				// Lose, game over
-				render(s, term, game)
+				observeGameFinished(game)
+				l.Info("game_lost")
+				reveal(v, game)
 				time.Sleep(time.Millisecond * 700)
-				warn(s, term, game.Answer)
-				repo.SaveGame(ctx, user, game)
-				renderStats(s, term, game, games)
+				warn(v, game.Answer)
+				saveGame()
+				showStats(game)
 				return
 			case err != nil:
 				// General error, warn and keep going
-				warn(s, term, err.Error())
-				repo.SaveGame(ctx, user, game)
-				fallthrough
+				metricRejectedGuesses.WithLabelValues(rejectReason(err)).Inc()
+				l.Debug("guess_rejected", "reason", rejectReason(err))
+				reject(v, game, v.errorText(err, normalize(game.Lang, word)))
 			default:
 				// Keep going
-				repo.SaveGame(ctx, user, game)
-				render(s, term, game)
+				saveGame()
+				reveal(v, game)
 			}
 		}
 	}
 }
 
-func render(s ssh.Session, term *terminal.Terminal, game *Game) {
-	clear(s)
-	print(s, term, "    Wordle\n")
+// sessionIDOf returns the SSH session hash stored on ctx by the ssh package.
+func sessionIDOf(ctx context.Context) string {
+	id, _ := ctx.Value(ssh.ContextKeySessionID).(string)
+	return id
+}
 
-	for _, word := range game.Guesses {
+// shortID truncates an SSH session ID to something readable in logs.
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
 
-	GUESSLETTERS:
-		for guessIndex, c := range word {
-			var (
-				letter      = string(c)
-				letterBoxed = fmt.Sprintf("[%s]", letter)
-			)
-
-			letterInWord := false
-			for answerIndex, answerLetter := range strings.Split(game.Answer, "") {
-				if letter == answerLetter && guessIndex == answerIndex {
-					printGreen(s, term, letterBoxed)
-					continue GUESSLETTERS
-				}
+// rejectReason maps a guess error to a short metric label.
+func rejectReason(err error) string {
+	switch {
+	case errors.Is(err, ErrWordLength):
+		return "length"
+	case errors.Is(err, ErrInvalidWord):
+		return "invalid_word"
+	case errors.Is(err, ErrTooManyGuesses):
+		return "rate_limited"
+	default:
+		return "other"
+	}
+}
 
-				if letter == answerLetter {
-					letterInWord = true
-				}
-			}
+func render(v *view, game *Game) {
+	if v.accessible {
+		announce(v, game)
+		return
+	}
+	v.draw(func() {
+		clear(v.s)
+		drawBoard(v, game)
+	})
+}
 
-			if letterInWord {
-				printYellow(s, term, letterBoxed)
-			} else {
-				print(s, term, letterBoxed)
-			}
+func drawBoard(v *view, game *Game) {
+	rows := []string{"    " + v.t("title")}
+	if v.compact() {
+		rows[0] = v.t("title")
+	}
+
+	for _, word := range game.Guesses {
+		var (
+			row     strings.Builder
+			letters = []rune(word)
+		)
+		for i, state := range game.Score(word) {
+			row.WriteString(v.tile(state, string(letters[i])))
 		}
-		print(s, term, "\n") // Newline for each word.
+		rows = append(rows, row.String())
+	}
+
+	if v.tui && !game.IsDone() {
+		// The row being typed into.
+		rows = append(rows, v.inputRow(v.pending))
+	}
+
+	for i := len(rows) - 1; i < MaxGuesses; i++ {
+		// Rows of empty boxes for each remaining guess.
+		rows = append(rows, v.inputRow(""))
+	}
+
+	// Rows are padded to the board width rather than centred as a block so
+	// that drawRow can find them again.
+	pad := v.pad(v.boardWidth())
+	for _, row := range rows {
+		print(v, pad+row+"\n")
 	}
 
-	for i := len(game.Guesses); i < MaxGuesses; i++ {
-		// Print rows of empty boxes for each remaining guess.
-		print(s, term, "[ ][ ][ ][ ][ ]\n")
+	if v.showKeyboard() {
+		print(v, "\n")
+		drawKeyboard(v, game)
 	}
 }
 
-func renderStats(s ssh.Session, term *terminal.Terminal, game *Game, games Games) {
-	render(s, term, game)
-	print(s, term, "\n    Statistics\n")
-	print(s, term, fmt.Sprintf("played..................%d\n", games.Played()))
-	print(s, term, fmt.Sprintf("win %%...................%d\n", games.WinPercent()))
-	print(s, term, fmt.Sprintf("current streak..........%d\n", games.CurrentStreak()))
-	print(s, term, fmt.Sprintf("max streak..............%d\n", games.MaxStreak()))
-	print(s, term, "guess distribution.......\n")
-	for i, val := range games.GuessDistribution() {
-		print(s, term, fmt.Sprintf("    %d...................%d\n", i+1, val))
+// drawKeyboard shows every letter coloured by what is known about it.
+func drawKeyboard(v *view, game *Game) {
+	var (
+		known = game.LetterStates()
+		rows  = []string{"qwertyuiop", "asdfghjkl", "zxcvbnm"}
+		lines = make([]string, 0, len(rows)+1)
+		sep   = " "
+	)
+	if extra := keptLetters[game.Lang]; extra != "" {
+		rows = append(rows, extra)
+	}
+	if v.compact() {
+		sep = ""
 	}
 
+	for i, keys := range rows {
+		var line strings.Builder
+		// Stagger the rows like a real keyboard.
+		line.WriteString(strings.Repeat(" ", i*len(sep)))
+		for j, c := range keys {
+			if j > 0 {
+				line.WriteString(sep)
+			}
+			line.WriteString(v.theme.Key(known[c], string(c)))
+		}
+		lines = append(lines, line.String())
+	}
+	v.writeBlock(lines)
+}
+
+func renderStats(v *view, game *Game, stats PlayerStats, today PuzzleStats) {
+	if v.accessible {
+		announce(v, game)
+		print(v, v.t("statistics")+". "+describeStats(v, stats)+" "+describePuzzle(v, game, today)+"\n")
+		return
+	}
+	v.draw(func() {
+		clear(v.s)
+		drawBoard(v, game)
+		drawStats(v, stats, game, today)
+	})
+}
+
+func drawStats(v *view, stats PlayerStats, game *Game, today PuzzleStats) {
 	var (
 		now   = time.Now()
 		hours = (24 - now.Hour()) - 1
 		mins  = 60 - now.Minute()
+		// latest is the distribution row of the game just played.
+		latest = 0
 	)
-	print(s, term, fmt.Sprintf("\nNext Wordle in %d hours %d mins\n", hours, mins))
+	if game.Won {
+		latest = len(game.Guesses)
+	}
+
+	if v.compact() {
+		lines := []string{
+			"",
+			v.t("statistics"),
+			fmt.Sprintf("%s %d", v.t("played"), stats.Played),
+			fmt.Sprintf("%s %d", v.t("win_percent"), stats.WinPercent()),
+			fmt.Sprintf("%s %d", v.t("current_streak"), stats.Streak(now)),
+			fmt.Sprintf("%s %d", v.t("max_streak"), stats.MaxStreak),
+		}
+		if stats.Freezes > 0 {
+			lines = append(lines, fmt.Sprintf("%s %d", v.t("streak_freezes"), stats.Freezes))
+		}
+		lines = append(lines, histogram(v.theme, stats.Distribution, latest, compactBarWidth)...)
+		if today.Played > 0 {
+			lines = append(lines, "", v.t("today_short", today.WinPercent(), today.Played), percentileText(v, game, today))
+		}
+		lines = append(lines, "", v.t("next_wordle_short", hours, mins))
+		if v.tui && !game.Imported {
+			lines = append(lines, replayHint(v))
+		}
+		v.writeBlock(lines)
+		return
+	}
+
+	lines := []string{
+		"",
+		"    " + v.t("statistics"),
+		leader(v.t("played"), stats.Played),
+		leader(v.t("win_percent"), stats.WinPercent()),
+		leader(v.t("current_streak"), stats.Streak(now)),
+		leader(v.t("max_streak"), stats.MaxStreak),
+	}
+	if stats.Freezes > 0 {
+		lines = append(lines, leader(v.t("streak_freezes"), stats.Freezes))
+	}
+
+	// Next to each bar goes the share of today's players who won in as many
+	// guesses, if the terminal is wide enough.
+	bars := histogram(v.theme, stats.Distribution, latest, statsWidth-6)
+	width := statsWidth + 1
+	for _, bar := range bars {
+		if n := visibleLen(bar) + 4; n > width {
+			width = n
+		}
+	}
+	column := today.Played > 0 && (v.width == 0 || width+len("  100%") <= v.width)
+	header := padDots(v.t("guess_distribution"), statsWidth+1)
+	if column {
+		header += strings.Repeat(" ", width-visibleLen(header)+2) + v.t("today")
+	}
+	lines = append(lines, header)
+	for i, bar := range bars {
+		line := "    " + bar
+		if column {
+			line += fmt.Sprintf("%s%3d%%", strings.Repeat(" ", width-visibleLen(line)+2), today.Share(i+1))
+		}
+		lines = append(lines, line)
+	}
+
+	if today.Played > 0 {
+		lines = append(lines, "",
+			v.t("today_summary", today.Played, today.WinPercent(), today.AverageGuesses()),
+			percentileText(v, game, today))
+	}
+	lines = append(lines, "", v.t("next_wordle", hours, mins))
+	if v.tui && !game.Imported {
+		// offerReplay waits for the player after the statistics.
+		lines = append(lines, replayHint(v))
+	}
+	v.writeBlock(lines)
 }
 
-func warn(s ssh.Session, term *terminal.Terminal, text string) {
-	clear(s)
-	printRed(s, term, text)
-	time.Sleep(time.Millisecond * 500)
-	clear(s)
+// percentileText tells the player how their game compares with everyone
+// else's today.
+func percentileText(v *view, game *Game, today PuzzleStats) string {
+	percentile, ok := today.Percentile(*game)
+	if !ok {
+		return v.t("first_today")
+	}
+	if v.compact() {
+		return v.t("percentile_short", percentile)
+	}
+	return v.t("percentile", percentile)
 }
 
-func warnGreen(s ssh.Session, term *terminal.Terminal, text string) {
-	clear(s)
-	printGreen(s, term, text)
-	time.Sleep(time.Millisecond * 500)
-	clear(s)
+// statsWidth is where the values start in the statistics table, and
+// compactBarWidth the longest histogram bar on narrow terminals.
+const (
+	statsWidth      = 24
+	compactBarWidth = 12
+)
+
+// leader joins a label and its value with a dot leader, e.g.
+// "played..................12".
+func leader(label string, value int) string {
+	return fmt.Sprintf("%s%d", padDots(label, statsWidth), value)
 }
 
-func print(s ssh.Session, term *terminal.Terminal, text string) {
-	io.WriteString(s, text)
+func padDots(label string, width int) string {
+	if n := width - utf8.RuneCountInString(label); n > 0 {
+		return label + strings.Repeat(".", n)
+	}
+	return label + "."
 }
 
-func printRed(s ssh.Session, term *terminal.Terminal, text string) {
-	text = fmt.Sprintf("%s%s%s", term.Escape.Red, text, term.Escape.Reset)
-	print(s, term, text)
+func warn(v *view, text string) {
+	if v.accessible {
+		print(v, text+"\n")
+		return
+	}
+	v.flash(func() {
+		clear(v.s)
+		v.writeBlock([]string{v.theme.ErrorText(text)})
+		time.Sleep(time.Millisecond * 500)
+		clear(v.s)
+	})
 }
 
-func printGreen(s ssh.Session, term *terminal.Terminal, text string) {
-	text = fmt.Sprintf("%s%s%s", term.Escape.Green, text, term.Escape.Reset)
-	print(s, term, text)
+func warnGreen(v *view, text string) {
+	if v.accessible {
+		print(v, strings.TrimSpace(text)+"\n")
+		return
+	}
+	v.flash(func() {
+		clear(v.s)
+		v.writeBlock([]string{v.theme.SuccessText(text)})
+		time.Sleep(time.Millisecond * 500)
+		clear(v.s)
+	})
 }
 
-func printYellow(s ssh.Session, term *terminal.Terminal, text string) {
-	text = fmt.Sprintf("%s%s%s", term.Escape.Yellow, text, term.Escape.Reset)
-	print(s, term, text)
+func print(v *view, text string) {
+	v.write(text)
 }
 
 func clear(s ssh.Session) {
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gliderlabs/ssh"
+)
+
+// liveSession is a connected player tracked by the sessionRegistry.
+type liveSession struct {
+	ID      string
+	User    string
+	Remote  string
+	Key     string // fingerprint of the key the player authenticated with, if any
+	Started time.Time
+
+	sess ssh.Session
+	// notify shows a message in the player's view; see view.notice.
+	notify func(string)
+
+	// mu guards game and locale, which the handler sets while other
+	// goroutines (shutdown, admin commands) may need to read or save them.
+	mu     sync.Mutex
+	game   *Game
+	locale string
+}
+
+// SetGame records the game the session is playing.
+func (ls *liveSession) SetGame(game *Game) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	ls.game = game
+}
+
+// SetLocale records the player's language, for messages sent to them from
+// outside their handler.
+func (ls *liveSession) SetLocale(locale string) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	ls.locale = locale
+}
+
+// t formats the message key in the player's locale.
+func (ls *liveSession) t(key string, args ...interface{}) string {
+	ls.mu.Lock()
+	locale := ls.locale
+	ls.mu.Unlock()
+	return translate(locale, key, args...)
+}
+
+// saveGame persists the session's game if it is in progress, and reports
+// whether there was one to save.
+func (ls *liveSession) saveGame(ctx context.Context, repo *sqliteRepo) (bool, error) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	if ls.game == nil || len(ls.game.Guesses) == 0 || ls.game.IsDone() {
+		return false, nil
+	}
+	return true, repo.SaveGame(ctx, ls.User, ls.game)
+}
+
+// Notify shows a message to the player without interrupting their game.
+func (ls *liveSession) Notify(text string) {
+	if ls.notify != nil {
+		ls.notify(text)
+		return
+	}
+	io.WriteString(ls.sess, "\r\n"+text+"\r\n")
+}
+
+// Progress describes how far through their game the player is, without
+// giving away the answer.
+func (ls *liveSession) Progress() string {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	switch {
+	case ls.game == nil:
+		return "-"
+	case ls.game.Won:
+		return "won"
+	case ls.game.IsDone():
+		return "lost"
+	}
+	return fmt.Sprintf("%d/%d", len(ls.game.Guesses), MaxGuesses)
+}
+
+// Kick tells the player why and disconnects them.
+func (ls *liveSession) Kick(reason string) {
+	ls.Notify(reason)
+	ls.sess.Close()
+}
+
+// sessionRegistry tracks every connected session so the server can reach
+// them from outside their handler goroutine.
+type sessionRegistry struct {
+	mu       sync.Mutex
+	sessions map[string]*liveSession
+
+	// announcement is shown to players who connect before it expires.
+	announcement      string
+	announcementUntil time.Time
+}
+
+func newSessionRegistry() *sessionRegistry {
+	return &sessionRegistry{sessions: make(map[string]*liveSession)}
+}
+
+// Add registers a session and returns a function that deregisters it. It
+// returns ErrTooManySessions instead if the player already has max
+// sessions, unless max is 0. Counting and adding under one lock keeps
+// connections made at the same time from all getting in under the cap.
+func (r *sessionRegistry) Add(ls *liveSession, max int) (func(), error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if max > 0 && r.countUser(ls.User) >= max {
+		return nil, ErrTooManySessions
+	}
+	r.sessions[ls.ID] = ls
+
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.sessions, ls.ID)
+	}, nil
+}
+
+// List returns a snapshot of the live sessions, oldest first.
+func (r *sessionRegistry) List() []*liveSession {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	list := make([]*liveSession, 0, len(r.sessions))
+	for _, ls := range r.sessions {
+		list = append(list, ls)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Started.Before(list[j].Started) })
+	return list
+}
+
+// Len returns the number of live sessions.
+func (r *sessionRegistry) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.sessions)
+}
+
+// CountUser returns the number of live sessions belonging to user.
+func (r *sessionRegistry) CountUser(user string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.countUser(user)
+}
+
+func (r *sessionRegistry) countUser(user string) int {
+	n := 0
+	for _, ls := range r.sessions {
+		if ls.User == user {
+			n++
+		}
+	}
+	return n
+}
+
+// Broadcast writes text to every live session.
+func (r *sessionRegistry) Broadcast(text string) {
+	for _, ls := range r.List() {
+		ls.Notify(text)
+	}
+}
+
+// Announce broadcasts text and keeps showing it to players who connect in
+// the next d, replacing any earlier announcement.
+func (r *sessionRegistry) Announce(text string, d time.Duration) {
+	r.mu.Lock()
+	r.announcement, r.announcementUntil = text, time.Now().Add(d)
+	r.mu.Unlock()
+
+	r.Broadcast(text)
+}
+
+// Announcement returns the current announcement, or "" if it has expired.
+func (r *sessionRegistry) Announcement() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if time.Now().After(r.announcementUntil) {
+		return ""
+	}
+	return r.announcement
+}
+
+// SaveGames persists the in-progress game of every live session.
+func (r *sessionRegistry) SaveGames(ctx context.Context, repo *sqliteRepo) {
+	for _, ls := range r.List() {
+		if _, err := ls.saveGame(ctx, repo); err != nil {
+			logs.Error("save_game_failed", "session", shortID(ls.ID), "player", ls.User, "err", err)
+		}
+	}
+}
+
+// SaveGamesForRestart saves every in-progress game and only then tells
+// each player, in their language, that the server restarts in timeout and
+// whether their game was saved.
+func (r *sessionRegistry) SaveGamesForRestart(ctx context.Context, repo *sqliteRepo, timeout time.Duration) {
+	for _, ls := range r.List() {
+		saved, err := ls.saveGame(ctx, repo)
+		switch {
+		case err != nil:
+			logs.Error("save_game_failed", "session", shortID(ls.ID), "player", ls.User, "err", err)
+			ls.Notify(ls.t("restarting_not_saved", timeout))
+		case saved:
+			ls.Notify(ls.t("restarting_saved", timeout))
+		default:
+			ls.Notify(ls.t("restarting", timeout))
+		}
+	}
+}
+
+// Wait blocks until every session has disconnected or ctx is done.
+func (r *sessionRegistry) Wait(ctx context.Context) error {
+	return r.waitUntil(ctx, func() bool { return r.Len() == 0 })
+}
+
+// WaitClosed blocks until sessions have all ended, after their handlers
+// saved their games, or ctx is done.
+func (r *sessionRegistry) WaitClosed(ctx context.Context, sessions []*liveSession) error {
+	return r.waitUntil(ctx, func() bool {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		for _, ls := range sessions {
+			if r.sessions[ls.ID] == ls {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func (r *sessionRegistry) waitUntil(ctx context.Context, done func() bool) error {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for !done() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
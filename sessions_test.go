@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitClosed(t *testing.T) {
+	sessions := newSessionRegistry()
+	alice := &liveSession{ID: "a", User: "alice"}
+	remove, err := sessions.Add(alice, 0)
+	require.NoError(t, err)
+	_, err = sessions.Add(&liveSession{ID: "b", User: "bob"}, 0)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	assert.Error(t, sessions.WaitClosed(ctx, []*liveSession{alice}))
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		remove()
+	}()
+	assert.NoError(t, sessions.WaitClosed(context.Background(), []*liveSession{alice}))
+	assert.NoError(t, sessions.WaitClosed(context.Background(), nil))
+}
+
+func TestSaveGamesForRestart(t *testing.T) {
+	ctx := context.Background()
+	repo, err := newRepo(filepath.Join(t.TempDir(), "wordle.db"), StreakConfig{})
+	require.NoError(t, err)
+	defer repo.Close()
+
+	var (
+		sessions = newSessionRegistry()
+		notices  = make(map[string]string)
+		saved    = make(map[string]int)
+	)
+	join := func(user, locale string, guesses ...string) {
+		ls := &liveSession{ID: user, User: user, Started: time.Now()}
+		ls.notify = func(text string) {
+			// Players are only told once their game is in the database.
+			games, err := repo.ListGames(ctx, user)
+			require.NoError(t, err)
+			notices[user], saved[user] = text, len(games)
+		}
+		ls.SetLocale(locale)
+		game := NewGame("crane")
+		for _, guess := range guesses {
+			game.Guess(guess)
+		}
+		ls.SetGame(game)
+		_, err := sessions.Add(ls, 0)
+		require.NoError(t, err)
+	}
+	join("alice", "de", "slate")
+	join("bob", defaultLocale)
+
+	sessions.SaveGamesForRestart(ctx, repo, 30*time.Second)
+	assert.Equal(t, "Der Server startet in 30s neu. Dein Spiel wurde gespeichert.", notices["alice"])
+	assert.Equal(t, 1, saved["alice"])
+	assert.Equal(t, "The server is restarting in 30s.", notices["bob"])
+	assert.Equal(t, 0, saved["bob"])
+
+	// Nobody is told their game was saved when it wasn't.
+	broken, err := newRepo(filepath.Join(t.TempDir(), "broken.db"), StreakConfig{})
+	require.NoError(t, err)
+	require.NoError(t, broken.Close())
+	sessions.SaveGamesForRestart(ctx, broken, 30*time.Second)
+	assert.Equal(t, "Der Server startet in 30s neu. Dein Spiel konnte nicht gespeichert werden.", notices["alice"])
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// puzzleEpoch is the day of the official game's puzzle 0. Puzzle numbers
+// count days from it, so they match the web game's.
+var puzzleEpoch = time.Date(2021, time.June, 19, 0, 0, 0, 0, time.UTC)
+
+// puzzleNumber returns the number of the puzzle played on the day of t.
+func puzzleNumber(t time.Time) int {
+	return int(t.UTC().Sub(puzzleEpoch).Hours() / 24)
+}
+
+// puzzleDate returns the day, in UTC, puzzle n was played.
+func puzzleDate(n int) time.Time {
+	return puzzleEpoch.AddDate(0, 0, n)
+}
+
+// unknownGuess stands in for the guesses of games imported from share grids,
+// which only record how each guess scored.
+const unknownGuess = "?????"
+
+var (
+	shareHeader = regexp.MustCompile(`^Wordle\s+([0-9][0-9,. ]*)\s+([1-6X])/6\*?$`)
+	// Dark, light and high contrast squares.
+	shareTiles = "🟩🟨⬛⬜🟧🟦"
+)
+
+// shareGrid is a result shared from the web game, e.g.
+//
+//	Wordle 1,234 3/6
+//
+//	⬛🟨⬛⬛⬛
+//	⬛🟩🟩🟨⬛
+//	🟩🟩🟩🟩🟩
+type shareGrid struct {
+	Puzzle  int
+	Guesses int
+	Won     bool
+}
+
+// parseShareGrids finds every share grid in text, which may hold any number
+// of them along with other text.
+func parseShareGrids(text string) ([]shareGrid, error) {
+	var (
+		grids []shareGrid
+		lines = strings.Split(strings.ReplaceAll(text, "\r", ""), "\n")
+	)
+	for i := 0; i < len(lines); i++ {
+		m := shareHeader.FindStringSubmatch(strings.TrimSpace(lines[i]))
+		if m == nil {
+			continue
+		}
+
+		puzzle, err := strconv.Atoi(strings.NewReplacer(",", "", ".", "", " ", "").Replace(m[1]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: bad puzzle number %q", i+1, m[1])
+		}
+		grid := shareGrid{Puzzle: puzzle, Guesses: MaxGuesses, Won: m[2] != "X"}
+		if grid.Won {
+			grid.Guesses, _ = strconv.Atoi(m[2])
+		}
+
+		// Count the rows of squares that follow, skipping the blank line.
+		rows := 0
+		for j := i + 1; j < len(lines); j++ {
+			row := strings.TrimSpace(lines[j])
+			if row == "" && rows == 0 {
+				continue
+			}
+			if !isShareRow(row) {
+				break
+			}
+			rows++
+			i = j
+		}
+		if rows != grid.Guesses {
+			return nil, fmt.Errorf("Wordle %d: want %d rows of squares, found %d", puzzle, grid.Guesses, rows)
+		}
+
+		grids = append(grids, grid)
+	}
+	return grids, nil
+}
+
+func isShareRow(row string) bool {
+	if row == "" {
+		return false
+	}
+	n := 0
+	for _, r := range row {
+		if !strings.ContainsRune(shareTiles, r) {
+			return false
+		}
+		n++
+	}
+	return n == WordLength
+}
+
+// Game rebuilds the game a share grid describes, whose answer was answer in
+// lang.
+func (grid shareGrid) Game(lang, answer string) Game {
+	var (
+		day  = puzzleDate(grid.Puzzle)
+		game = Game{
+			Answer:   answer,
+			Started:  day,
+			Finished: day,
+			Won:      grid.Won,
+			Lang:     lang,
+			Imported: true,
+		}
+	)
+	for i := 0; i < grid.Guesses; i++ {
+		game.Guesses = append(game.Guesses, unknownGuess)
+	}
+	if grid.Won {
+		game.Guesses[grid.Guesses-1] = answer
+	}
+	return game
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// speedrunMode races a puzzle of its own each day against the clock, with
+// `ssh host speedrun`. Each player gets one attempt a day.
+const speedrunMode = "speedrun"
+
+// leaderboardSize is how many times the leaderboards show.
+const leaderboardSize = 10
+
+// Clock times a speedrun on the server. It starts at the player's first
+// keypress rather than when the game is created, and keeps running if they
+// disconnect, so neither idling nor reconnecting buys time.
+type Clock struct {
+	Start time.Time
+	// Splits are the times from Start to each accepted guess, to the
+	// millisecond.
+	Splits []time.Duration
+}
+
+func (c *Clock) split(now time.Time) {
+	c.Splits = append(c.Splits, now.Sub(c.Start).Round(time.Millisecond))
+}
+
+// Elapsed returns how long a won speedrun took, or zero for any other game.
+func (g *Game) Elapsed() time.Duration {
+	if g.Clock == nil || !g.Won || len(g.Clock.Splits) == 0 {
+		return 0
+	}
+	return g.Clock.Splits[len(g.Clock.Splits)-1]
+}
+
+// speedrunSeed fixes the order speedrun answers are drawn in, which is apart
+// from the order of the daily answers.
+const speedrunSeed = 5318008
+
+// speedrunLookahead is how many days of coming daily answers a speedrun
+// avoids, so racing never shows a word before its day. Short word lists
+// avoid the next half of the list instead.
+const speedrunLookahead = 365
+
+// newSpeedrun creates the speedrun of the day of now in dict's language.
+func newSpeedrun(ctx context.Context, repo *sqliteRepo, dict *dictionary, now time.Time) *Game {
+	game := NewGame(speedrunWord(dict, now, scheduledWords(ctx, repo, dict.Lang, now)))
+	game.Lang = dict.Lang
+	game.Clock = &Clock{}
+	return game
+}
+
+// speedrunWord returns the answer of the speedrun of the day of now: the
+// next word in a seeded shuffle of the answers that is not a daily answer
+// in the coming speedrunLookahead days, nor one of the scheduled words.
+func speedrunWord(dict *dictionary, now time.Time, scheduled []string) string {
+	var (
+		n        = len(dict.Answers)
+		today    = dict.dayIndex(now)
+		ahead    = speedrunLookahead
+		upcoming = make(map[string]bool)
+	)
+	if ahead > n/2 {
+		ahead = n / 2
+	}
+	for i := 0; i <= ahead; i++ {
+		upcoming[dict.Answers[(today+i)%n]] = true
+	}
+	for _, word := range scheduled {
+		upcoming[word] = true
+	}
+
+	order := rand.New(rand.NewSource(speedrunSeed)).Perm(n)
+	day := puzzleNumber(now)
+	for i := 0; i < n; i++ {
+		if word := dict.Answers[order[((day+i)%n+n)%n]]; !upcoming[word] {
+			return word
+		}
+	}
+	return dict.Answers[order[(day%n+n)%n]]
+}
+
+// startClock starts a speedrun's clock at the player's first keypress, or
+// straight away without a PTY since lines only arrive once typed.
+func startClock(v *view, game *Game) error {
+	if v.tui {
+		v.flash(func() { v.showMessage(v.t("speedrun_start")) })
+		// Peek so the key still goes to the guess.
+		if _, err := v.keys.Peek(1); err != nil {
+			return err
+		}
+	} else {
+		print(v, v.t("clock_started")+"\n")
+	}
+	game.Clock.Start = time.Now()
+	return nil
+}
+
+// SpeedrunTime is a won speedrun on a leaderboard.
+type SpeedrunTime struct {
+	User    string
+	Puzzle  int
+	Elapsed time.Duration
+}
+
+// Speedrun returns user's speedrun of puzzle in lang, or nil if they have
+// not started it.
+func (r *sqliteRepo) Speedrun(ctx context.Context, user, lang string, puzzle int) (*Game, error) {
+	defer observeRepo("speedrun", time.Now())
+
+	var (
+		game Game
+		data []byte
+	)
+	const query = `SELECT id, data FROM speedrun WHERE user=? AND lang=? AND puzzle=?`
+	err := r.DB.QueryRowContext(ctx, query, user, lang, puzzle).Scan(&game.ID, &data)
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+
+	id := game.ID
+	if err := json.Unmarshal(data, &game); err != nil {
+		return nil, fmt.Errorf("failed to decode speedrun %d", id)
+	}
+	game.ID = id
+	return &game, nil
+}
+
+// SaveSpeedrun inserts or updates a speedrun, recording its time once won.
+func (r *sqliteRepo) SaveSpeedrun(ctx context.Context, user string, game *Game) error {
+	defer observeRepo("save_speedrun", time.Now())
+
+	data, err := json.Marshal(game)
+	if err != nil {
+		return err
+	}
+	var millis sql.NullInt64
+	if game.Won {
+		millis = sql.NullInt64{Int64: game.Elapsed().Milliseconds(), Valid: true}
+	}
+
+	if game.ID != 0 {
+		_, err := r.DB.ExecContext(ctx, `UPDATE speedrun SET millis=?, data=?, updated_at=CURRENT_TIMESTAMP WHERE id=?`, millis, data, game.ID)
+		return err
+	}
+	const insert = `INSERT INTO speedrun(user, lang, puzzle, millis, data) VALUES(?, ?, ?, ?, ?)`
+	res, err := r.DB.ExecContext(ctx, insert, user, langOf(*game), puzzleNumber(game.Started), millis, data)
+	if err != nil {
+		return err
+	}
+	game.ID, err = res.LastInsertId()
+	return err
+}
+
+// mergeSpeedruns moves from's speedruns to into. A player has one speedrun
+// per puzzle, so where both have one, into keeps the faster win.
+func mergeSpeedruns(ctx context.Context, tx *sql.Tx, from, into string) error {
+	const dropSlower = `DELETE FROM speedrun WHERE user=? AND EXISTS (
+		SELECT 1 FROM speedrun AS other WHERE other.user=? AND other.lang=speedrun.lang AND other.puzzle=speedrun.puzzle
+			AND other.millis IS NOT NULL AND (speedrun.millis IS NULL OR other.millis < speedrun.millis))`
+	if _, err := tx.ExecContext(ctx, dropSlower, into, from); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE OR IGNORE speedrun SET user=? WHERE user=?`, into, from); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx, `DELETE FROM speedrun WHERE user=?`, from)
+	return err
+}
+
+// FastestSpeedruns returns the fastest wins of puzzle in lang, fastest first.
+func (r *sqliteRepo) FastestSpeedruns(ctx context.Context, lang string, puzzle, limit int) ([]SpeedrunTime, error) {
+	defer observeRepo("fastest_speedruns", time.Now())
+
+	const query = `SELECT user, puzzle, millis FROM speedrun
+		WHERE lang=? AND puzzle=? AND millis IS NOT NULL ORDER BY millis, id LIMIT ?`
+	return r.speedrunTimes(ctx, query, lang, puzzle, limit)
+}
+
+// BestSpeedruns returns each player's fastest win of any puzzle in lang,
+// fastest first.
+func (r *sqliteRepo) BestSpeedruns(ctx context.Context, lang string, limit int) ([]SpeedrunTime, error) {
+	defer observeRepo("best_speedruns", time.Now())
+
+	// SQLite takes the other columns from the row with the minimum.
+	const query = `SELECT user, puzzle, MIN(millis) FROM speedrun
+		WHERE lang=? AND millis IS NOT NULL GROUP BY user ORDER BY MIN(millis), user LIMIT ?`
+	return r.speedrunTimes(ctx, query, lang, limit)
+}
+
+func (r *sqliteRepo) speedrunTimes(ctx context.Context, query string, args ...interface{}) ([]SpeedrunTime, error) {
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var times []SpeedrunTime
+	for rows.Next() {
+		var (
+			t      SpeedrunTime
+			millis int64
+		)
+		if err := rows.Scan(&t.User, &t.Puzzle, &millis); err != nil {
+			return nil, err
+		}
+		t.Elapsed = time.Duration(millis) * time.Millisecond
+		times = append(times, t)
+	}
+	return times, rows.Err()
+}
+
+// renderSpeedrun shows a finished speedrun's time and splits under the
+// board, along with the day's fastest.
+func renderSpeedrun(v *view, game *Game, fastest []SpeedrunTime) {
+	lines := speedrunLines(v, game, fastest)
+	if v.accessible {
+		announce(v, game)
+		print(v, strings.Join(lines, "\n")+"\n")
+		return
+	}
+	v.draw(func() {
+		clear(v.s)
+		drawBoard(v, game)
+		v.writeBlock(append([]string{"", "    " + v.t("speedrun")}, lines...))
+	})
+}
+
+func speedrunLines(v *view, game *Game, fastest []SpeedrunTime) []string {
+	var lines []string
+	switch {
+	case game.Won && v.accessible:
+		lines = append(lines, v.t("time")+" "+formatElapsed(game.Elapsed()))
+	case game.Won:
+		lines = append(lines, padDots(v.t("time"), statsWidth)+formatElapsed(game.Elapsed()))
+	default:
+		lines = append(lines, v.t("not_solved"))
+	}
+	splits := make([]string, 0, len(game.Clock.Splits))
+	for _, split := range game.Clock.Splits {
+		splits = append(splits, formatElapsed(split))
+	}
+	if len(splits) > 0 {
+		lines = append(lines, v.t("splits")+" "+strings.Join(splits, " "))
+	}
+
+	if len(fastest) > 0 {
+		lines = append(lines, "", v.t("fastest_today"))
+		lines = append(lines, leaderboardLines(fastest, false)...)
+	}
+	if v.tui {
+		lines = append(lines, "", replayHint(v))
+	}
+	return lines
+}
+
+// leaderboardLines lists times with the players' names, and the puzzle
+// numbers if withPuzzle is set.
+func leaderboardLines(times []SpeedrunTime, withPuzzle bool) []string {
+	lines := make([]string, 0, len(times))
+	for i, t := range times {
+		line := fmt.Sprintf("%2d. %-12s %9s", i+1, playerName(t.User), formatElapsed(t.Elapsed))
+		if withPuzzle {
+			line += fmt.Sprintf("  #%d", t.Puzzle)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// leaderboardCommand shows the fastest speedruns of today and of all time,
+// e.g. `ssh host leaderboard`.
+func leaderboardCommand(ctx context.Context, v *view, repo *sqliteRepo) int {
+	var (
+		lang   = dictionaryFor(v.locale).Lang
+		puzzle = puzzleNumber(time.Now())
+	)
+	today, err := repo.FastestSpeedruns(ctx, lang, puzzle, leaderboardSize)
+	if err != nil {
+		logs.Error("fastest_speedruns_failed", "err", err)
+		print(v, v.t("load_leaderboard_failed")+"\n")
+		return 1
+	}
+	best, err := repo.BestSpeedruns(ctx, lang, leaderboardSize)
+	if err != nil {
+		logs.Error("best_speedruns_failed", "err", err)
+		print(v, v.t("load_leaderboard_failed")+"\n")
+		return 1
+	}
+
+	print(v, fmt.Sprintf("%s (#%d)\n", v.t("fastest_today"), puzzle))
+	if len(today) == 0 {
+		print(v, "  "+v.t("no_speedruns_today")+"\n")
+	}
+	for _, line := range leaderboardLines(today, false) {
+		print(v, "  "+line+"\n")
+	}
+	if len(best) > 0 {
+		print(v, "\n"+v.t("fastest_ever")+"\n")
+		for _, line := range leaderboardLines(best, true) {
+			print(v, "  "+line+"\n")
+		}
+	}
+	return 0
+}
+
+// formatElapsed writes a time as minutes, seconds and milliseconds, e.g.
+// "1:05.250".
+func formatElapsed(d time.Duration) string {
+	d = d.Round(time.Millisecond)
+	return fmt.Sprintf("%d:%02d.%03d", int(d/time.Minute), int(d%time.Minute/time.Second), int(d%time.Second/time.Millisecond))
+}
+
+// playerName is the name a player is shown to others by, without the IP
+// address of their player key.
+func playerName(user string) string {
+	if i := strings.LastIndex(user, "|"); i >= 0 {
+		return user[:i]
+	}
+	return user
+}
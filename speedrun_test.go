@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpeedrunClock(t *testing.T) {
+	game := NewGame("ultra")
+	game.Clock = &Clock{Start: time.Now().Add(-time.Minute)}
+
+	game.Guess("crane")
+	game.Guess("moist")
+	assert.Zero(t, game.Elapsed())
+	game.Guess("water")
+	_, won := game.Guess("ultra")
+	require.True(t, won)
+
+	require.Len(t, game.Clock.Splits, 4)
+	assert.Equal(t, game.Clock.Splits[3], game.Elapsed())
+	assert.True(t, game.Elapsed() >= time.Minute)
+	assert.Zero(t, game.Elapsed()%time.Millisecond)
+
+	assert.Equal(t, "1:05.250", formatElapsed(65250*time.Millisecond))
+	assert.Equal(t, "0:00.042", formatElapsed(42*time.Millisecond))
+	assert.Equal(t, "bob", playerName("bob|127.0.0.1"))
+}
+
+func TestSpeedrunWord(t *testing.T) {
+	var (
+		dict = newDictionary("en", strings.Fields("aback abase abate abbey abbot abhor abide abled abode abort"), nil)
+		day  = time.Date(2026, time.October, 1, 12, 0, 0, 0, time.UTC)
+	)
+	for i := 0; i < 30; i++ {
+		now := day.AddDate(0, 0, i)
+		word := speedrunWord(dict, now, nil)
+		assert.Equal(t, word, speedrunWord(dict, now.Add(time.Hour), nil))
+		// Half of this list is as far ahead as there is to avoid.
+		for ahead := 0; ahead <= 5; ahead++ {
+			assert.NotEqual(t, dict.WordOfTheDay(now.AddDate(0, 0, ahead)), word, "day %d", i)
+		}
+	}
+
+	en := dictionaryFor(defaultLocale)
+	word := speedrunWord(en, day, nil)
+	for ahead := 0; ahead <= speedrunLookahead; ahead++ {
+		require.NotEqual(t, en.WordOfTheDay(day.AddDate(0, 0, ahead)), word)
+	}
+
+	// Nor does it give away a word an admin scheduled.
+	other := speedrunWord(en, day, []string{word})
+	assert.NotEqual(t, word, other)
+	assert.Equal(t, other, speedrunWord(en, day, []string{word, "zzzzz"}))
+}
+
+func TestScheduleSpeedrunAnswer(t *testing.T) {
+	ctx := context.Background()
+	repo, err := newRepo(filepath.Join(t.TempDir(), "wordle.db"), StreakConfig{})
+	require.NoError(t, err)
+	defer repo.Close()
+
+	var (
+		now      = time.Now()
+		tomorrow = now.UTC().AddDate(0, 0, 1).Format(dateLayout)
+		word     = speedrunWord(dictionaryFor(defaultLocale), now, nil)
+		admin    = &adminConsole{repo: repo}
+	)
+	game := newSpeedrun(ctx, repo, dictionaryFor(defaultLocale), now)
+	assert.Equal(t, word, game.Answer)
+
+	// Scheduling today's speedrun answer would change the speedrun.
+	out := &fakeSession{}
+	assert.Equal(t, 1, admin.scheduleCommand(ctx, &view{locale: defaultLocale, s: out}, "root", []string{tomorrow, word}))
+	assert.Contains(t, out.out.String(), "today's speedrun answer")
+	entries, err := repo.ListSchedule(ctx, tomorrow)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestSpeedrunLeaderboard(t *testing.T) {
+	ctx := context.Background()
+	repo, err := newRepo(filepath.Join(t.TempDir(), "wordle.db"), StreakConfig{})
+	require.NoError(t, err)
+	defer repo.Close()
+
+	day := time.Date(2026, time.October, 1, 12, 0, 0, 0, time.UTC)
+	race := func(user string, started time.Time, elapsed time.Duration, guesses ...string) *Game {
+		game := NewGame("ultra")
+		game.Started = started
+		game.Clock = &Clock{Start: started}
+		require.NoError(t, repo.SaveSpeedrun(ctx, user, game))
+		for _, guess := range guesses {
+			game.Guess(guess)
+		}
+		game.Clock.Splits[len(game.Clock.Splits)-1] = elapsed
+		require.NoError(t, repo.SaveSpeedrun(ctx, user, game))
+		return game
+	}
+
+	race("alice|127.0.0.1", day, 40*time.Second, "crane", "ultra")
+	race("bob|127.0.0.1", day, 30*time.Second, "ultra")
+	race("carol|127.0.0.1", day, 20*time.Second, "crane", "moist", "water", "slate", "bloke", "crane")
+	race("alice|127.0.0.1", day.AddDate(0, 0, 1), 10*time.Second, "ultra")
+
+	game, err := repo.Speedrun(ctx, "alice|127.0.0.1", "en", puzzleNumber(day))
+	require.NoError(t, err)
+	require.NotNil(t, game)
+	assert.Equal(t, 40*time.Second, game.Elapsed())
+	game, err = repo.Speedrun(ctx, "carol|127.0.0.1", "en", puzzleNumber(day.AddDate(0, 0, 1)))
+	require.NoError(t, err)
+	assert.Nil(t, game)
+
+	fastest, err := repo.FastestSpeedruns(ctx, "en", puzzleNumber(day), 10)
+	require.NoError(t, err)
+	assert.Equal(t, []SpeedrunTime{
+		{User: "bob|127.0.0.1", Puzzle: puzzleNumber(day), Elapsed: 30 * time.Second},
+		{User: "alice|127.0.0.1", Puzzle: puzzleNumber(day), Elapsed: 40 * time.Second},
+	}, fastest)
+
+	best, err := repo.BestSpeedruns(ctx, "en", 10)
+	require.NoError(t, err)
+	assert.Equal(t, []SpeedrunTime{
+		{User: "alice|127.0.0.1", Puzzle: puzzleNumber(day) + 1, Elapsed: 10 * time.Second},
+		{User: "bob|127.0.0.1", Puzzle: puzzleNumber(day), Elapsed: 30 * time.Second},
+	}, best)
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"time"
+)
+
+// PlayerStats summarizes a player's finished games in one language. SaveGame
+// keeps it up to date as games finish, so showing statistics takes a single
+// read however many games the player has.
+type PlayerStats struct {
+	Played int
+	Wins   int
+	// CurrentStreak counts the wins on consecutive days up to the last
+	// game; use Streak for the streak as of today.
+	CurrentStreak int
+	MaxStreak     int
+	// Freezes are earned streak freezes the player has not used yet.
+	Freezes int `json:",omitempty"`
+	// Distribution counts wins by the number of guesses they took.
+	Distribution [MaxGuesses]int
+	// Last is when the most recently played finished game started.
+	Last time.Time
+}
+
+// WinPercent returns the share of games won, rounded down.
+func (s PlayerStats) WinPercent() int {
+	if s.Played == 0 {
+		return 0
+	}
+	return s.Wins * 100 / s.Played
+}
+
+// Streak returns the current streak as of now. Missing a day breaks it,
+// unless the player has a freeze for every day missed; today's puzzle has
+// until the end of the day.
+func (s PlayerStats) Streak(now time.Time) int {
+	if s.Played == 0 || puzzleNumber(now)-puzzleNumber(s.Last)-1 > s.Freezes {
+		return 0
+	}
+	return s.CurrentStreak
+}
+
+// Add counts a finished game played after every game already counted, using
+// freezes for any days missed since the last one and earning them under
+// rules.
+func (s *PlayerStats) Add(game Game, rules StreakConfig) {
+	if s.Played > 0 {
+		if missed := puzzleNumber(game.Started) - puzzleNumber(s.Last) - 1; missed > 0 {
+			if missed <= s.Freezes {
+				s.Freezes -= missed
+			} else {
+				s.CurrentStreak = 0
+			}
+		}
+	}
+
+	s.Played++
+	if game.Won {
+		s.Wins++
+		s.CurrentStreak++
+		s.Distribution[len(game.Guesses)-1]++
+		if rules.FreezeEvery > 0 && s.CurrentStreak%rules.FreezeEvery == 0 && s.Freezes < rules.MaxFreezes {
+			s.Freezes++
+		}
+	} else {
+		s.CurrentStreak = 0
+	}
+	if s.CurrentStreak > s.MaxStreak {
+		s.MaxStreak = s.CurrentStreak
+	}
+	s.Last = game.Started
+}
+
+// newPlayerStats computes the statistics of games, which may be in any order
+// and include unfinished games.
+func newPlayerStats(games Games, rules StreakConfig) PlayerStats {
+	var stats PlayerStats
+	for _, game := range games.finished() {
+		stats.Add(game, rules)
+	}
+	return stats
+}
+
+// PlayerStats returns user's statistics for lang, or zero PlayerStats if they
+// have not finished a game in it.
+func (r *sqliteRepo) PlayerStats(ctx context.Context, user, lang string) (PlayerStats, error) {
+	defer observeRepo("player_stats", time.Now())
+
+	return playerStats(ctx, r.DB, user, lang)
+}
+
+// RebuildStats recomputes the statistics of user, or of every player and
+// puzzle if user is empty, from their games. It returns how many players
+// were rebuilt.
+func (r *sqliteRepo) RebuildStats(ctx context.Context, user string) (int, error) {
+	defer observeRepo("rebuild_stats", time.Now())
+
+	users := []string{user}
+	if user == "" {
+		rows, err := r.DB.QueryContext(ctx, `SELECT DISTINCT user FROM game UNION SELECT user FROM player_stats`)
+		if err != nil {
+			return 0, err
+		}
+		users = users[:0]
+		for rows.Next() {
+			var u string
+			if err := rows.Scan(&u); err != nil {
+				rows.Close()
+				return 0, err
+			}
+			users = append(users, u)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return 0, err
+		}
+		if err := rebuildAllPuzzleStats(ctx, r.DB); err != nil {
+			return 0, err
+		}
+	}
+
+	// One transaction per player keeps the server's writes from waiting on
+	// a rebuild of the whole database.
+	for _, u := range users {
+		tx, err := r.DB.BeginTx(ctx, nil)
+		if err != nil {
+			return 0, err
+		}
+		if err := rebuildStats(ctx, tx, u, r.streaks); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		if err := tx.Commit(); err != nil {
+			return 0, err
+		}
+	}
+	return len(users), nil
+}
+
+// querier is satisfied by both *sql.DB and *sql.Tx.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func playerStats(ctx context.Context, q querier, user, lang string) (PlayerStats, error) {
+	var (
+		stats PlayerStats
+		data  []byte
+	)
+	err := q.QueryRowContext(ctx, `SELECT data FROM player_stats WHERE user=? AND lang=?`, user, lang).Scan(&data)
+	switch {
+	case err == sql.ErrNoRows:
+		return stats, nil
+	case err != nil:
+		return stats, err
+	}
+
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return stats, fmt.Errorf("failed to decode stats")
+	}
+	return stats, nil
+}
+
+func savePlayerStats(ctx context.Context, q querier, user, lang string, stats PlayerStats) error {
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return err
+	}
+
+	const upsert = `INSERT INTO player_stats(user, lang, data) VALUES(?, ?, ?)
+		ON CONFLICT(user, lang) DO UPDATE SET data=excluded.data, updated_at=CURRENT_TIMESTAMP`
+	_, err = q.ExecContext(ctx, upsert, user, lang, data)
+	return err
+}
+
+// countFinishedGame adds a game that has just finished to user's statistics.
+// Games finishing in order are counted in place; an older game, such as an
+// imported one, changes the streaks, so the statistics are recomputed.
+func countFinishedGame(ctx context.Context, q querier, user string, game *Game, rules StreakConfig) error {
+	lang := langOf(*game)
+	stats, err := playerStats(ctx, q, user, lang)
+	if err != nil {
+		return err
+	}
+	if game.Started.Before(stats.Last) {
+		return rebuildStats(ctx, q, user, rules)
+	}
+	stats.Add(*game, rules)
+	return savePlayerStats(ctx, q, user, lang, stats)
+}
+
+// rebuildStats recomputes user's statistics in every language from their
+// games.
+func rebuildStats(ctx context.Context, q querier, user string, rules StreakConfig) error {
+	rows, err := q.QueryContext(ctx, `SELECT data FROM game WHERE user=?`, user)
+	if err != nil {
+		return err
+	}
+	byLang := make(map[string]Games)
+	for rows.Next() {
+		var (
+			data []byte
+			game Game
+		)
+		if err := rows.Scan(&data); err != nil {
+			rows.Close()
+			return err
+		}
+		if err := json.Unmarshal(data, &game); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to decode game")
+		}
+		byLang[langOf(game)] = append(byLang[langOf(game)], game)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if _, err := q.ExecContext(ctx, `DELETE FROM player_stats WHERE user=?`, user); err != nil {
+		return err
+	}
+	for lang, games := range byLang {
+		if err := savePlayerStats(ctx, q, user, lang, newPlayerStats(games, rules)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runRebuildStats implements the `wordle rebuild-stats` subcommand.
+func runRebuildStats(ctx context.Context, repo *sqliteRepo, args []string) error {
+	var (
+		flags = flag.NewFlagSet("rebuild-stats", flag.ExitOnError)
+		user  = flags.String("user", "", "only rebuild this player's statistics")
+	)
+	flags.Parse(args)
+
+	n, err := repo.RebuildStats(ctx, *user)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("rebuilt statistics for %s\n", plural(n, "player", "players"))
+	return nil
+}
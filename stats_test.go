@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlayerStatsKeptBySaveGame(t *testing.T) {
+	ctx := context.Background()
+	repo, err := newRepo(filepath.Join(t.TempDir(), "wordle.db"), StreakConfig{})
+	require.NoError(t, err)
+	defer repo.Close()
+
+	day := time.Date(2026, time.October, 1, 12, 0, 0, 0, time.UTC)
+	play := func(started time.Time, guesses ...string) *Game {
+		game := NewGame("ultra")
+		game.Started = started
+		require.NoError(t, repo.SaveGame(ctx, "alice", game))
+		for _, guess := range guesses {
+			game.Guess(guess)
+			require.NoError(t, repo.SaveGame(ctx, "alice", game))
+		}
+		return game
+	}
+
+	play(day, "crane", "ultra")
+	play(day.AddDate(0, 0, 1), "ultra")
+	play(day.AddDate(0, 0, 2), "crane")
+
+	stats, err := repo.PlayerStats(ctx, "alice", "en")
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.Played)
+	assert.Equal(t, 2, stats.CurrentStreak)
+	assert.Equal(t, [MaxGuesses]int{1, 1, 0, 0, 0, 0}, stats.Distribution)
+
+	// A lost game from before the others breaks nothing but the win rate.
+	lost := play(day.AddDate(0, 0, -1), "crane", "crane", "crane", "crane", "crane", "crane")
+	stats, err = repo.PlayerStats(ctx, "alice", "en")
+	require.NoError(t, err)
+	assert.Equal(t, PlayerStats{Played: 3, Wins: 2, CurrentStreak: 2, MaxStreak: 2, Distribution: stats.Distribution, Last: stats.Last}, stats)
+	assert.Equal(t, 66, stats.WinPercent())
+
+	require.NoError(t, repo.DeleteGame(ctx, lost.ID))
+	_, err = repo.DB.Exec(`DELETE FROM player_stats`)
+	require.NoError(t, err)
+	n, err := repo.RebuildStats(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	stats, err = repo.PlayerStats(ctx, "alice", "en")
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.Played)
+	assert.Equal(t, 100, stats.WinPercent())
+}
+func TestBackfillStatsWithFreezes(t *testing.T) {
+	ctx := context.Background()
+	file := filepath.Join(t.TempDir(), "wordle.db")
+	rules := StreakConfig{FreezeEvery: 3, MaxFreezes: 1}
+	repo, err := newRepo(file, rules)
+	require.NoError(t, err)
+
+	day := time.Now().AddDate(0, 0, -3)
+	for i := 0; i < 3; i++ {
+		game := NewGame("ultra")
+		game.Started = day.AddDate(0, 0, i)
+		game.Guess("ultra")
+		require.NoError(t, repo.SaveGame(ctx, "alice", game))
+	}
+	// As if the database was from before statistics were kept.
+	_, err = repo.DB.Exec(`DELETE FROM player_stats`)
+	require.NoError(t, err)
+	require.NoError(t, repo.Close())
+
+	repo, err = newRepo(file, rules)
+	require.NoError(t, err)
+	defer repo.Close()
+	stats, err := repo.PlayerStats(ctx, "alice", "en")
+	require.NoError(t, err)
+	assert.Equal(t, 3, stats.Played)
+	assert.Equal(t, 1, stats.Freezes)
+}
+
+func TestStreakFreezes(t *testing.T) {
+	today := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	rules := StreakConfig{FreezeEvery: 3, MaxFreezes: 1}
+	var tests = []struct {
+		Name    string
+		Days    string
+		Streak  int
+		Freezes int
+	}{
+		{"earned", "WWW", 3, 1},
+		{"held at most max", "WWWWWW", 6, 1},
+		{"used on a missed day", "WWW.WW", 5, 0},
+		{"too many days missed", "WWW..W", 1, 1},
+		{"kept through a loss", "WWWL", 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			stats := newPlayerStats(dailyGames(today, tt.Days), rules)
+			assert.Equal(t, tt.Streak, stats.Streak(today))
+			assert.Equal(t, tt.Freezes, stats.Freezes)
+		})
+	}
+
+	// An unused freeze covers yesterday until the next game.
+	stats := newPlayerStats(dailyGames(today.AddDate(0, 0, -2), "WWW"), rules)
+	assert.Equal(t, 3, stats.Streak(today))
+	assert.Equal(t, 0, stats.Streak(today.AddDate(0, 0, 1)))
+}
@@ -5,26 +5,38 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/gliderlabs/ssh"
 	_ "github.com/mattn/go-sqlite3"
 )
 
-func newRepo(dbFile string) (*sqliteRepo, error) {
-	db, err := sql.Open("sqlite3", dbFile)
+func newRepo(dbFile string, streaks StreakConfig) (*sqliteRepo, error) {
+	// WAL lets backups and readers run alongside the game's writes, and the
+	// busy timeout makes writers wait for each other instead of failing.
+	sep := "?"
+	if strings.Contains(dbFile, "?") {
+		sep = "&"
+	}
+	db, err := sql.Open("sqlite3", dbFile+sep+"_journal_mode=WAL&_busy_timeout=5000")
 	if err != nil {
 		return nil, err
 	}
 
 	r := sqliteRepo{
-		dbFile: dbFile,
-		DB:     db,
+		dbFile:  dbFile,
+		DB:      db,
+		streaks: streaks,
 	}
 
 	if err := r.createSchema(); err != nil {
 		return nil, err
 	}
+	if err := r.backfillStats(); err != nil {
+		return nil, err
+	}
 
 	return &r, nil
 }
@@ -32,50 +44,113 @@ func newRepo(dbFile string) (*sqliteRepo, error) {
 type sqliteRepo struct {
 	dbFile string
 	DB     *sql.DB
+
+	// auditLog enables recording of auditEvents in the audit table.
+	auditLog bool
+	// streaks are the rules for earning streak freezes.
+	streaks StreakConfig
+}
+
+const (
+	auditConnect    = "connect"
+	auditDisconnect = "disconnect"
+	auditGuess      = "guess"
+	auditAdmin      = "admin"
+	auditSchedule   = "schedule"
+)
+
+// auditEvent is a single entry in the audit trail.
+type auditEvent struct {
+	Event   string
+	Session string
+	User    string
+	Remote  string
+	Detail  string
+}
+
+// Audit records ev in the audit table. It is a no-op unless auditing is enabled.
+func (r *sqliteRepo) Audit(ctx context.Context, ev auditEvent) error {
+	if !r.auditLog {
+		return nil
+	}
+	defer observeRepo("audit", time.Now())
+
+	const insert = `INSERT INTO audit(event, session, user, remote, detail) VALUES(?, ?, ?, ?, ?)`
+	if _, err := r.DB.ExecContext(ctx, insert, ev.Event, ev.Session, ev.User, ev.Remote, ev.Detail); err != nil {
+		return err
+	}
+
+	return nil
 }
 
+// SaveGame inserts or updates game, and counts it in user's statistics in
+// the same transaction once it is finished.
 func (r *sqliteRepo) SaveGame(ctx context.Context, userID string, game *Game) error {
+	defer observeRepo("save_game", time.Now())
+
 	const (
 		insert = `INSERT INTO game(user, data) VALUES(?, ?)`
 		update = `UPDATE game SET data=? WHERE id=?`
 	)
 
+	data, err := json.Marshal(game)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	wasDone := false
 	switch {
 	case game.ID != 0:
-		stmt, err := r.DB.Prepare(update)
-		if err != nil {
+		var saved []byte
+		err := tx.QueryRowContext(ctx, `SELECT data FROM game WHERE id=?`, game.ID).Scan(&saved)
+		if err != nil && err != sql.ErrNoRows {
 			return err
 		}
-
-		data, err := json.Marshal(game)
-		if err != nil {
-			return err
+		if saved != nil {
+			var previous Game
+			if err := json.Unmarshal(saved, &previous); err != nil {
+				return fmt.Errorf("failed to decode game %d", game.ID)
+			}
+			wasDone = previous.IsDone()
 		}
 
-		if _, err := stmt.Exec(data, game.ID); err != nil {
+		if _, err := tx.ExecContext(ctx, update, data, game.ID); err != nil {
 			return err
 		}
 
 	default:
-		stmt, err := r.DB.Prepare(insert)
+		res, err := tx.ExecContext(ctx, insert, userID, data)
 		if err != nil {
 			return err
 		}
 
-		data, err := json.Marshal(game)
-		if err != nil {
+		// Remember the row so later saves update it instead of inserting again.
+		if game.ID, err = res.LastInsertId(); err != nil {
 			return err
 		}
+	}
 
-		if _, err := stmt.Exec(userID, data); err != nil {
+	if game.IsDone() && !wasDone {
+		if err := countFinishedGame(ctx, tx, userID, game, r.streaks); err != nil {
+			return err
+		}
+		if err := countPuzzleGame(ctx, tx, game); err != nil {
 			return err
 		}
 	}
 
-	return nil
+	return tx.Commit()
 }
 
 func (r *sqliteRepo) ListGames(ctx context.Context, user string) (Games, error) {
+	defer observeRepo("list_games", time.Now())
+
 	var (
 		query string
 		rows  *sql.Rows
@@ -112,9 +187,328 @@ func (r *sqliteRepo) ListGames(ctx context.Context, user string) (Games, error)
 		games = append(games, game)
 	}
 
+	// Imported games can be newer rows than the games they predate.
+	sort.SliceStable(games, func(i, j int) bool { return games[i].Started.After(games[j].Started) })
+
 	return games, nil
 }
 
+// PlayerGame is a game along with the player it belongs to.
+type PlayerGame struct {
+	User string
+	Game
+}
+
+// ListPlayerGames returns the games of user, or of every player if user is
+// empty, oldest first.
+func (r *sqliteRepo) ListPlayerGames(ctx context.Context, user string) ([]PlayerGame, error) {
+	defer observeRepo("list_player_games", time.Now())
+
+	query, args := `SELECT id, user, data FROM game ORDER BY id`, []interface{}{}
+	if user != "" {
+		query, args = `SELECT id, user, data FROM game WHERE user=? ORDER BY id`, []interface{}{user}
+	}
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var games []PlayerGame
+	for rows.Next() {
+		var (
+			pg   PlayerGame
+			data []byte
+		)
+		if err := rows.Scan(&pg.ID, &pg.User, &data); err != nil {
+			return nil, err
+		}
+		id := pg.ID
+		if err := json.Unmarshal(data, &pg.Game); err != nil {
+			return nil, fmt.Errorf("failed to decode game %d", id)
+		}
+		pg.ID = id
+		games = append(games, pg)
+	}
+
+	return games, rows.Err()
+}
+
+// DeleteGame removes a game, e.g. so a player can start today's again.
+func (r *sqliteRepo) DeleteGame(ctx context.Context, id int64) error {
+	defer observeRepo("delete_game", time.Now())
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var (
+		user string
+		data []byte
+		game Game
+	)
+	err = tx.QueryRowContext(ctx, `SELECT user, data FROM game WHERE id=?`, id).Scan(&user, &data)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &game); err != nil {
+		return fmt.Errorf("failed to decode game %d", id)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM game WHERE id=?`, id); err != nil {
+		return err
+	}
+	if err := rebuildStats(ctx, tx, user, r.streaks); err != nil {
+		return err
+	}
+	if err := rebuildPuzzleStats(ctx, tx, game); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// MergePlayers moves every game of from to into, along with from's settings
+// and group if into has none. Speedruns move too; where both raced the same
+// puzzle, the faster win is kept. It returns the number of games moved.
+func (r *sqliteRepo) MergePlayers(ctx context.Context, from, into string) (int64, error) {
+	defer observeRepo("merge_players", time.Now())
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `UPDATE game SET user=? WHERE user=?`, into, from)
+	if err != nil {
+		return 0, err
+	}
+	moved, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	const copySettings = `INSERT OR IGNORE INTO settings(user, data) SELECT ?, data FROM settings WHERE user=?`
+	if _, err := tx.ExecContext(ctx, copySettings, into, from); err != nil {
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM settings WHERE user=?`, from); err != nil {
+		return 0, err
+	}
+	const copyGroup = `INSERT OR IGNORE INTO player_group(user, name, joined_at) SELECT ?, name, joined_at FROM player_group WHERE user=?`
+	if _, err := tx.ExecContext(ctx, copyGroup, into, from); err != nil {
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM player_group WHERE user=?`, from); err != nil {
+		return 0, err
+	}
+	if err := mergeSpeedruns(ctx, tx, from, into); err != nil {
+		return 0, err
+	}
+	for _, user := range []string{from, into} {
+		if err := rebuildStats(ctx, tx, user, r.streaks); err != nil {
+			return 0, err
+		}
+	}
+
+	return moved, tx.Commit()
+}
+
+// ServerStats summarizes the database for the admin console.
+type ServerStats struct {
+	Players    int
+	Games      int
+	GamesSince int
+	Bans       int
+}
+
+// ServerStats counts players, games, games created since the given time and bans.
+func (r *sqliteRepo) ServerStats(ctx context.Context, since time.Time) (ServerStats, error) {
+	defer observeRepo("server_stats", time.Now())
+
+	var stats ServerStats
+	const query = `SELECT COUNT(DISTINCT user), COUNT(*), COALESCE(SUM(created_at >= ?), 0) FROM game`
+	// created_at is stored by SQLite as UTC text.
+	err := r.DB.QueryRowContext(ctx, query, since.UTC().Format("2006-01-02 15:04:05")).Scan(&stats.Players, &stats.Games, &stats.GamesSince)
+	if err != nil {
+		return stats, err
+	}
+	if err := r.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM ban`).Scan(&stats.Bans); err != nil {
+		return stats, err
+	}
+
+	return stats, nil
+}
+
+// Settings are a player's saved preferences.
+type Settings struct {
+	Theme      string `json:",omitempty"`
+	Accessible bool   `json:",omitempty"`
+	Locale     string `json:",omitempty"`
+}
+
+// GetSettings returns the saved settings for user, or zero Settings if they
+// have never saved any.
+func (r *sqliteRepo) GetSettings(ctx context.Context, user string) (Settings, error) {
+	defer observeRepo("get_settings", time.Now())
+
+	var (
+		settings Settings
+		data     []byte
+	)
+	err := r.DB.QueryRowContext(ctx, `SELECT data FROM settings WHERE user=?`, user).Scan(&data)
+	switch {
+	case err == sql.ErrNoRows:
+		return settings, nil
+	case err != nil:
+		return settings, err
+	}
+
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return settings, fmt.Errorf("failed to decode settings")
+	}
+	return settings, nil
+}
+
+func (r *sqliteRepo) SaveSettings(ctx context.Context, user string, settings Settings) error {
+	defer observeRepo("save_settings", time.Now())
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+
+	const upsert = `INSERT INTO settings(user, data) VALUES(?, ?)
+		ON CONFLICT(user) DO UPDATE SET data=excluded.data, updated_at=CURRENT_TIMESTAMP`
+	if _, err := r.DB.ExecContext(ctx, upsert, user, data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AddBan adds ban to the ban list, replacing any existing ban on the same value.
+func (r *sqliteRepo) AddBan(ctx context.Context, ban Ban) error {
+	defer observeRepo("add_ban", time.Now())
+
+	const upsert = `INSERT INTO ban(kind, value, reason) VALUES(?, ?, ?)
+		ON CONFLICT(value) DO UPDATE SET kind=excluded.kind, reason=excluded.reason`
+	if _, err := r.DB.ExecContext(ctx, upsert, ban.Kind, ban.Value, ban.Reason); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RemoveBan lifts the ban on value. It reports whether a ban was removed.
+func (r *sqliteRepo) RemoveBan(ctx context.Context, value string) (bool, error) {
+	defer observeRepo("remove_ban", time.Now())
+
+	res, err := r.DB.ExecContext(ctx, `DELETE FROM ban WHERE value=?`, value)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
+
+func (r *sqliteRepo) ListBans(ctx context.Context) (Bans, error) {
+	defer observeRepo("list_bans", time.Now())
+
+	rows, err := r.DB.QueryContext(ctx, `SELECT kind, value, reason, created_at FROM ban ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	bans := make(Bans, 0)
+	for rows.Next() {
+		var ban Ban
+		if err := rows.Scan(&ban.Kind, &ban.Value, &ban.Reason, &ban.Created); err != nil {
+			return nil, err
+		}
+		bans = append(bans, ban)
+	}
+
+	return bans, rows.Err()
+}
+
+// ScheduleAnswer makes word the answer in lang on date, a YYYY-MM-DD day in
+// UTC, replacing anything already scheduled for that day.
+func (r *sqliteRepo) ScheduleAnswer(ctx context.Context, entry ScheduledAnswer) error {
+	defer observeRepo("schedule_answer", time.Now())
+
+	const upsert = `INSERT INTO schedule(date, lang, word, created_by) VALUES(?, ?, ?, ?)
+		ON CONFLICT(date, lang) DO UPDATE SET word=excluded.word, created_by=excluded.created_by, created_at=CURRENT_TIMESTAMP`
+	if _, err := r.DB.ExecContext(ctx, upsert, entry.Date, entry.Lang, entry.Word, entry.CreatedBy); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// UnscheduleAnswer removes the answer scheduled in lang on date. It reports
+// whether there was one.
+func (r *sqliteRepo) UnscheduleAnswer(ctx context.Context, date, lang string) (bool, error) {
+	defer observeRepo("unschedule_answer", time.Now())
+
+	res, err := r.DB.ExecContext(ctx, `DELETE FROM schedule WHERE date=? AND lang=?`, date, lang)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
+
+// ScheduledAnswerOn returns the answer scheduled in lang on date, or "" if
+// there is none.
+func (r *sqliteRepo) ScheduledAnswerOn(ctx context.Context, date, lang string) (string, error) {
+	defer observeRepo("scheduled_answer", time.Now())
+
+	var word string
+	err := r.DB.QueryRowContext(ctx, `SELECT word FROM schedule WHERE date=? AND lang=?`, date, lang).Scan(&word)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return word, err
+}
+
+// ListSchedule returns the answers scheduled on or after date, soonest first.
+func (r *sqliteRepo) ListSchedule(ctx context.Context, from string) ([]ScheduledAnswer, error) {
+	defer observeRepo("list_schedule", time.Now())
+
+	const query = `SELECT date, lang, word, created_by, created_at FROM schedule WHERE date >= ? ORDER BY date, lang`
+	rows, err := r.DB.QueryContext(ctx, query, from)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []ScheduledAnswer
+	for rows.Next() {
+		var entry ScheduledAnswer
+		if err := rows.Scan(&entry.Date, &entry.Lang, &entry.Word, &entry.CreatedBy, &entry.Created); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
 // Close closes the SQLite database connection.
 func (r *sqliteRepo) Close() error {
 	return r.DB.Close()
@@ -129,7 +523,75 @@ func (r *sqliteRepo) createSchema() error {
 		data BLOB NOT NULL,
 		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);
-	CREATE INDEX IF NOT EXISTS idx_game_user ON game(user);`
+	CREATE INDEX IF NOT EXISTS idx_game_user ON game(user);
+	CREATE TABLE IF NOT EXISTS audit(
+		id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+		event TEXT NOT NULL,
+		session TEXT NOT NULL,
+		user TEXT NOT NULL,
+		remote TEXT NOT NULL,
+		detail TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_audit_user ON audit(user);
+	CREATE TABLE IF NOT EXISTS settings(
+		user TEXT NOT NULL PRIMARY KEY,
+		data BLOB NOT NULL,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE TABLE IF NOT EXISTS player_stats(
+		user TEXT NOT NULL,
+		lang TEXT NOT NULL,
+		data BLOB NOT NULL,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY(user, lang)
+	);
+	CREATE TABLE IF NOT EXISTS puzzle_stats(
+		lang TEXT NOT NULL,
+		puzzle INTEGER NOT NULL,
+		data BLOB NOT NULL,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY(lang, puzzle)
+	);
+	CREATE TABLE IF NOT EXISTS speedrun(
+		id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+		user TEXT NOT NULL,
+		lang TEXT NOT NULL,
+		puzzle INTEGER NOT NULL,
+		millis INTEGER,
+		data BLOB NOT NULL,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(user, lang, puzzle)
+	);
+	CREATE INDEX IF NOT EXISTS idx_speedrun_millis ON speedrun(lang, puzzle, millis);
+	CREATE TABLE IF NOT EXISTS ban(
+		id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+		kind TEXT NOT NULL,
+		value TEXT NOT NULL UNIQUE,
+		reason TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE TABLE IF NOT EXISTS game_archive(
+		id INTEGER NOT NULL PRIMARY KEY,
+		user TEXT NOT NULL,
+		data BLOB NOT NULL,
+		created_at TIMESTAMP,
+		archived_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE TABLE IF NOT EXISTS schedule(
+		date TEXT NOT NULL,
+		lang TEXT NOT NULL,
+		word TEXT NOT NULL,
+		created_by TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY(date, lang)
+	);
+	CREATE TABLE IF NOT EXISTS player_group(
+		user TEXT NOT NULL PRIMARY KEY,
+		name TEXT NOT NULL,
+		joined_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_player_group_name ON player_group(name);`
 
 	if _, err := r.DB.Exec(schema); err != nil {
 		return err
@@ -138,6 +600,35 @@ func (r *sqliteRepo) createSchema() error {
 	return nil
 }
 
+// backfillStats computes the player and puzzle statistics the first time a
+// database from before they were kept is opened.
+func (r *sqliteRepo) backfillStats() error {
+	var players, puzzles bool
+	const query = `SELECT
+		NOT EXISTS(SELECT 1 FROM player_stats) AND EXISTS(SELECT 1 FROM game),
+		NOT EXISTS(SELECT 1 FROM puzzle_stats) AND EXISTS(SELECT 1 FROM game)`
+	if err := r.DB.QueryRow(query).Scan(&players, &puzzles); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	switch {
+	case players:
+		// Rebuilding every player rebuilds the puzzles too.
+		n, err := r.RebuildStats(ctx, "")
+		if err != nil {
+			return err
+		}
+		logs.Info("stats_backfilled", "players", n)
+	case puzzles:
+		if err := rebuildAllPuzzleStats(ctx, r.DB); err != nil {
+			return err
+		}
+		logs.Info("puzzle_stats_backfilled")
+	}
+	return nil
+}
+
 func userKey(s ssh.Session) string {
 	parts := strings.Split(s.RemoteAddr().String(), ":")
 	ip := parts[0]
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// theme decides how letters and messages are drawn. Colour themes paint the
+// letter or its background with an SGR sequence; the monochrome theme marks
+// letter states with brackets and text attributes instead.
+type theme struct {
+	// Name is also the key of its description, theme_<Name>, in the
+	// catalogs.
+	Name string
+
+	// SGR parameters for each letter state, e.g. "32" or "48;5;28".
+	Correct string
+	Present string
+	Absent  string
+	Unknown string
+
+	// KeyAbsent is used for eliminated letters on the keyboard.
+	KeyAbsent string
+
+	Error   string
+	Success string
+
+	// Background themes draw tiles as " a " on a coloured background
+	// rather than colouring "[a]".
+	Background bool
+	// Symbols themes mark tiles as [A] correct, (A) present and  A  absent,
+	// so they never rely on colour.
+	Symbols bool
+}
+
+var (
+	classicTheme = &theme{
+		Name:      "classic",
+		Correct:   "32",
+		Present:   "33",
+		KeyAbsent: "2",
+		Error:     "31",
+		Success:   "32",
+	}
+
+	themes = []*theme{
+		classicTheme,
+		{
+			Name:      "high-contrast",
+			Correct:   "1;38;5;208",
+			Present:   "1;38;5;33",
+			KeyAbsent: "2",
+			Error:     "1;38;5;196",
+			Success:   "1;38;5;208",
+		},
+		{
+			Name:      "mono",
+			Correct:   "7",
+			Present:   "4",
+			Absent:    "2",
+			KeyAbsent: "2",
+			Error:     "1",
+			Success:   "1",
+			Symbols:   true,
+		},
+		{
+			Name:       "blocks",
+			Correct:    "1;97;48;5;28",
+			Present:    "1;97;48;5;136",
+			Absent:     "1;97;48;5;240",
+			Unknown:    "1;97;48;5;236",
+			KeyAbsent:  "2",
+			Error:      "1;97;48;5;160",
+			Success:    "1;97;48;5;28",
+			Background: true,
+		},
+		{
+			Name:       "truecolor",
+			Correct:    "1;97;48;2;106;170;100",
+			Present:    "1;97;48;2;201;180;88",
+			Absent:     "1;97;48;2;120;124;126",
+			Unknown:    "1;97;48;2;58;58;60",
+			KeyAbsent:  "2",
+			Error:      "1;97;48;2;200;60;60",
+			Success:    "1;97;48;2;106;170;100",
+			Background: true,
+		},
+	}
+)
+
+// findTheme returns the theme called name, or the classic theme if there is
+// no such theme.
+func findTheme(name string) (*theme, bool) {
+	for _, t := range themes {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return classicTheme, false
+}
+
+func themeNames() []string {
+	names := make([]string, 0, len(themes))
+	for _, t := range themes {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+// tileWidth returns the width of a tile; compact layouts drop the brackets
+// where the theme can do without them.
+func (t *theme) tileWidth(compact bool) int {
+	if compact && !t.Symbols && !t.Background {
+		return 1
+	}
+	return 3
+}
+
+// Tile renders letter in the given state. A space is an empty tile.
+func (t *theme) Tile(state letterState, letter string, compact bool) string {
+	if t.Symbols {
+		up := strings.ToUpper(letter)
+		switch state {
+		case letterCorrect:
+			return t.paint(t.Correct, "["+up+"]")
+		case letterPresent:
+			return t.paint(t.Present, "("+up+")")
+		case letterAbsent:
+			return t.paint(t.Absent, " "+up+" ")
+		}
+		if letter == " " {
+			return " _ "
+		}
+		return " " + letter + " "
+	}
+
+	if t.Background {
+		return t.paint(t.sgr(state), " "+letter+" ")
+	}
+
+	if compact {
+		if letter == " " {
+			letter = "."
+		}
+		return t.paint(t.sgr(state), letter)
+	}
+	return t.paint(t.sgr(state), "["+letter+"]")
+}
+
+// Key renders a keyboard letter in the given state.
+func (t *theme) Key(state letterState, letter string) string {
+	switch state {
+	case letterAbsent:
+		return t.paint(t.KeyAbsent, letter)
+	case letterUnknown:
+		return letter
+	}
+	if t.Symbols {
+		letter = strings.ToUpper(letter)
+	}
+	return t.paint(t.sgr(state), letter)
+}
+
+func (t *theme) ErrorText(text string) string {
+	if t.Symbols {
+		text = "! " + text
+	}
+	return t.paint(t.Error, text)
+}
+
+func (t *theme) SuccessText(text string) string {
+	return t.paint(t.Success, text)
+}
+
+func (t *theme) sgr(state letterState) string {
+	switch state {
+	case letterCorrect:
+		return t.Correct
+	case letterPresent:
+		return t.Present
+	case letterAbsent:
+		return t.Absent
+	default:
+		return t.Unknown
+	}
+}
+
+// paint wraps text in the SGR sequence, resetting attributes afterwards.
+func (t *theme) paint(sgr, text string) string {
+	if sgr == "" {
+		return text
+	}
+	return fmt.Sprintf("\033[%sm%s\033[0m", sgr, text)
+}
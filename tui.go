@@ -0,0 +1,252 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyBackspace = 8
+	keyEnter     = '\r'
+	keyEscape    = 27
+	keyDelete    = 127
+
+	// Arrow keys are reported as runes from the private use area.
+	keyUp    = '\uE000'
+	keyDown  = '\uE001'
+	keyRight = '\uE002'
+	keyLeft  = '\uE003'
+
+	hideCursor = "\033[?25l"
+	showCursor = "\033[?25h"
+)
+
+// readGuess reads the next guess. With a PTY the letters are typed straight
+// into the current board row; without one it falls back to a line prompt.
+func readGuess(v *view, game *Game) (string, error) {
+	if !v.tui {
+		return v.readLine()
+	}
+
+	for {
+		key, err := readKey(v.keys)
+		if err != nil {
+			return "", err
+		}
+
+		v.mu.Lock()
+		switch {
+		case key == keyCtrlC || key == keyCtrlD:
+			v.mu.Unlock()
+			return "", io.EOF
+		case key == keyEnter || key == '\n':
+			word := v.pending
+			v.mu.Unlock()
+			return word, nil
+		case key == keyBackspace || key == keyDelete:
+			if letters := []rune(v.pending); len(letters) > 0 {
+				v.pending = string(letters[:len(letters)-1])
+			}
+		case key == '/' && v.pending == "":
+			v.mu.Unlock()
+			command, err := readShortcut(v)
+			if err != nil || command != "" {
+				return command, err
+			}
+			continue
+		case unicode.IsLetter(key):
+			if utf8.RuneCountInString(v.pending) < WordLength {
+				v.pending += normalize(game.Lang, string(key))
+			}
+		}
+		v.clearMessage()
+		v.drawRow(len(game.Guesses), v.inputRow(v.pending), 0)
+		v.mu.Unlock()
+	}
+}
+
+// readShortcut reads a shortcut such as /calendar on the message line once
+// '/' has been typed. It returns "" if the player deletes the '/'.
+func readShortcut(v *view) (string, error) {
+	command := "/"
+	for {
+		v.flash(func() { v.showMessage(command) })
+
+		key, err := readKey(v.keys)
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case key == keyCtrlC || key == keyCtrlD:
+			return "", io.EOF
+		case key == keyEnter || key == '\n':
+			v.flash(v.clearMessage)
+			return command, nil
+		case key == keyBackspace || key == keyDelete:
+			_, size := utf8.DecodeLastRuneInString(command)
+			if command = command[:len(command)-size]; command == "" {
+				v.flash(v.clearMessage)
+				return "", nil
+			}
+		case unicode.IsLetter(key) || unicode.IsDigit(key):
+			command += string(unicode.ToLower(key))
+		case key == ' ' || strings.ContainsRune("-_.", key):
+			// For arguments, as in /replay <player>.
+			command += string(key)
+		}
+	}
+}
+
+// readKey returns the next key pressed, reporting the arrow keys as keyUp,
+// keyDown, keyRight and keyLeft and skipping other escape sequences.
+func readKey(r *bufio.Reader) (rune, error) {
+	for {
+		key, _, err := r.ReadRune()
+		if err != nil {
+			return 0, err
+		}
+		if key != keyEscape {
+			return key, nil
+		}
+
+		// CSI sequences end with a byte in the range @ to ~.
+		next, _, err := r.ReadRune()
+		if err != nil {
+			return 0, err
+		}
+		if next != '[' && next != 'O' {
+			continue
+		}
+		for params := 0; ; params++ {
+			b, err := r.ReadByte()
+			if err != nil {
+				return 0, err
+			}
+			if b >= '@' && b <= '~' {
+				if arrow := strings.IndexByte("ABCD", b); arrow >= 0 && params == 0 {
+					return keyUp + rune(arrow), nil
+				}
+				break
+			}
+		}
+	}
+}
+
+// reveal shows the latest guess. In TUI mode the tiles of the row flip over
+// one at a time before the whole board is redrawn.
+func reveal(v *view, game *Game) {
+	if v.tui {
+		v.flash(func() {
+			var (
+				row     = len(game.Guesses) - 1
+				letters = []rune(game.Guesses[row])
+				score   = game.Score(game.Guesses[row])
+			)
+			v.pending = ""
+			for i := range letters {
+				var b strings.Builder
+				for j := range letters {
+					state := letterUnknown
+					if j <= i {
+						state = score[j]
+					}
+					b.WriteString(v.tile(state, string(letters[j])))
+				}
+				v.drawRow(row, b.String(), 0)
+				time.Sleep(time.Millisecond * 150)
+			}
+		})
+	}
+	render(v, game)
+}
+
+// reject tells the player why a guess was not accepted. In TUI mode the
+// current row shakes in place and the reason appears under the board, with
+// the typed letters kept for editing.
+func reject(v *view, game *Game, text string) {
+	if v.accessible {
+		warn(v, text)
+		return
+	}
+	if !v.tui {
+		warn(v, text)
+		render(v, game)
+		return
+	}
+
+	v.flash(func() {
+		var (
+			row    = len(game.Guesses)
+			shaken = v.theme.paint(v.theme.Error, v.inputRow(v.pending))
+		)
+		for _, offset := range []int{1, -1, 1, -1, 0} {
+			v.drawRow(row, shaken, offset)
+			time.Sleep(time.Millisecond * 50)
+		}
+		v.drawRow(row, v.inputRow(v.pending), 0)
+		v.showMessage(v.theme.ErrorText(text))
+	})
+}
+
+// inputRow renders the letters typed so far followed by empty tiles.
+func (v *view) inputRow(pending string) string {
+	var (
+		b       strings.Builder
+		letters = []rune(pending)
+	)
+	for i := 0; i < WordLength; i++ {
+		letter := " "
+		if i < len(letters) {
+			letter = string(letters[i])
+		}
+		b.WriteString(v.tile(letterUnknown, letter))
+	}
+	return b.String()
+}
+
+// tile renders one letter of a row in the current layout.
+func (v *view) tile(state letterState, letter string) string {
+	return v.theme.Tile(state, letter, v.compact())
+}
+
+// drawRow overwrites board row i in place, shifted offset columns. It must
+// be called with mu held.
+func (v *view) drawRow(i int, text string, offset int) {
+	var (
+		line = 2 + i // below the title
+		col  = len(v.pad(v.boardWidth())) + 1 + offset
+	)
+	if col < 1 {
+		col = 1
+	}
+	v.write(moveTo(line, 1) + "\033[2K" + moveTo(line, col) + text)
+}
+
+// showMessage writes text on the line below the board and keyboard. It must
+// be called with mu held.
+func (v *view) showMessage(text string) {
+	v.write(moveTo(v.messageLine(), 1) + "\033[2K" + v.pad(visibleLen(text)) + text)
+}
+
+func (v *view) clearMessage() {
+	v.write(moveTo(v.messageLine(), 1) + "\033[2K")
+}
+
+func (v *view) messageLine() int {
+	line := 2 + MaxGuesses + 1
+	if v.showKeyboard() {
+		line += 4
+	}
+	return line
+}
+
+func moveTo(line, col int) string {
+	return fmt.Sprintf("\033[%d;%dH", line, col)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/gliderlabs/ssh"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSession records what a view writes to it.
+type fakeSession struct {
+	ssh.Session
+	out bytes.Buffer
+}
+
+func (s *fakeSession) Write(p []byte) (int, error) { return s.out.Write(p) }
+
+func TestReadShortcut(t *testing.T) {
+	read := func(keys string) string {
+		v := &view{s: &fakeSession{}, keys: bufio.NewReader(strings.NewReader(keys)), locale: defaultLocale}
+		command, err := readShortcut(v)
+		require.NoError(t, err)
+		return command
+	}
+
+	assert.Equal(t, "/calendar", read("Calendar\r"))
+	// Backspace takes off a whole letter, however many bytes it is.
+	assert.Equal(t, "/cax", read("cañ\x7fx\r"))
+	assert.Equal(t, "", read("ß\x7f\x7f"))
+}
+
+func TestReadGuess(t *testing.T) {
+	read := func(lang, keys string) (string, *fakeSession, error) {
+		s := &fakeSession{}
+		v := &view{s: s, tui: true, keys: bufio.NewReader(strings.NewReader(keys)), theme: classicTheme, locale: defaultLocale}
+		game := NewGame("water")
+		game.Lang = lang
+		word, err := readGuess(v, game)
+		return word, s, err
+	}
+
+	// Letters go straight into the row, lowercased and at most a word long.
+	word, s, err := read(defaultLocale, "CRanesx\r")
+	require.NoError(t, err)
+	assert.Equal(t, "crane", word)
+	assert.Contains(t, ansiEscape.ReplaceAllString(s.out.String(), ""), "[c][r][a][n][e]")
+
+	// Backspace and delete take letters off; anything else is ignored.
+	word, _, err = read(defaultLocale, "cra1 \x7f\x08ow\x1b[D\r")
+	require.NoError(t, err)
+	assert.Equal(t, "cow", word)
+
+	// Accents fold, except for the letters of the language.
+	word, _, err = read("es", "ÑÁñez\r")
+	require.NoError(t, err)
+	assert.Equal(t, "ñañez", word)
+
+	_, _, err = read(defaultLocale, "cr\x03")
+	assert.Equal(t, io.EOF, err)
+	_, _, err = read(defaultLocale, "cr")
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestReadKey(t *testing.T) {
+	keys := bufio.NewReader(strings.NewReader("a\x1b[A\x1bOB\x1b[1;5C\x1b[Dñ"))
+	for _, want := range []rune{'a', keyUp, keyDown, keyLeft, 'ñ'} {
+		key, err := readKey(keys)
+		require.NoError(t, err)
+		assert.Equal(t, want, key)
+	}
+	_, err := readKey(keys)
+	assert.Equal(t, io.EOF, err)
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/gliderlabs/ssh"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+const (
+	// compactWidth is the narrowest terminal that fits the boxed board and
+	// statistics; below it the board is drawn without brackets.
+	compactWidth = 28
+	// keyboardHeight is the shortest terminal that fits the board, the
+	// keyboard and the prompt.
+	keyboardHeight = 14
+)
+
+var ansiEscape = regexp.MustCompile("\033\\[[0-9;]*[A-Za-z]")
+
+// view is a player's terminal. It knows the window size, if the client
+// requested a PTY, and remembers the last screen drawn so it can be redrawn
+// when the window is resized.
+//
+// With a PTY the view runs as a full-screen TUI reading raw keys; without
+// one it falls back to a line editor with a "> " prompt.
+type view struct {
+	s    ssh.Session
+	term *terminal.Terminal
+	pty  bool
+	tui  bool
+	keys *bufio.Reader
+
+	// accessible views describe the game in words; see accessible.go.
+	accessible bool
+	introduced bool
+	announced  int // guesses already described
+
+	// theme and locale are set once, before the first draw.
+	theme  *theme
+	locale string
+
+	mu        sync.Mutex
+	width     int // zero when there is no PTY
+	height    int
+	last      func()
+	prompting bool
+	pending   string // letters typed into the current row in TUI mode
+}
+
+func newView(s ssh.Session) *view {
+	v := &view{
+		s:      s,
+		term:   terminal.NewTerminal(s, ""),
+		theme:  classicTheme,
+		locale: defaultLocale,
+	}
+	v.term.SetPrompt("> ")
+
+	pty, winCh, ok := s.Pty()
+	if !ok {
+		return v
+	}
+	v.resize(pty.Window)
+	v.pty = true
+	v.tui = true
+	v.keys = bufio.NewReader(s)
+	v.write(hideCursor)
+
+	go func() {
+		for win := range winCh {
+			v.mu.Lock()
+			v.resize(win)
+			if v.last != nil {
+				v.last()
+			}
+			if v.prompting && !v.accessible {
+				// The redraw cleared the prompt the terminal printed.
+				io.WriteString(v.s, v.prompt())
+			}
+			v.mu.Unlock()
+		}
+	}()
+
+	return v
+}
+
+// close restores the terminal state changed by the view.
+func (v *view) close() {
+	if v.tui {
+		v.write(showCursor)
+	}
+}
+
+// resize must be called with mu held, or before the view is shared.
+func (v *view) resize(win ssh.Window) {
+	v.width, v.height = win.Width, win.Height
+	v.term.SetSize(win.Width, win.Height)
+	v.term.SetPrompt(v.prompt())
+}
+
+func (v *view) prompt() string {
+	return v.pad(v.boardWidth()) + "> "
+}
+
+// readLine reads a line of input at the prompt.
+func (v *view) readLine() (string, error) {
+	v.mu.Lock()
+	v.prompting = true
+	v.mu.Unlock()
+
+	defer func() {
+		v.mu.Lock()
+		v.prompting = false
+		v.mu.Unlock()
+	}()
+
+	return v.term.ReadLine()
+}
+
+// draw runs fn with the view locked and remembers it for redraws.
+func (v *view) draw(fn func()) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.last = fn
+	fn()
+}
+
+// flash runs fn with the view locked, without remembering it for redraws.
+func (v *view) flash(fn func()) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	fn()
+}
+
+// compact reports whether the terminal is too narrow for the boxed layout.
+func (v *view) compact() bool {
+	return v.width > 0 && v.width < compactWidth
+}
+
+// boardWidth returns the width of a board row in the current layout.
+func (v *view) boardWidth() int {
+	return WordLength * v.theme.tileWidth(v.compact())
+}
+
+// showKeyboard reports whether there is room for the keyboard.
+func (v *view) showKeyboard() bool {
+	return v.height == 0 || v.height >= keyboardHeight
+}
+
+// pad returns the spaces needed to centre a block width columns wide.
+func (v *view) pad(width int) string {
+	if v.accessible || v.width <= width {
+		return ""
+	}
+	return strings.Repeat(" ", (v.width-width)/2)
+}
+
+// writeBlock writes lines centred as a block, so that their left edges stay
+// aligned with each other.
+func (v *view) writeBlock(lines []string) {
+	width := 0
+	for _, line := range lines {
+		if n := visibleLen(line); n > width {
+			width = n
+		}
+	}
+
+	pad := v.pad(width)
+	for _, line := range lines {
+		v.write(pad + line + "\n")
+	}
+}
+
+// write writes text to the session. A PTY client's terminal is in raw mode,
+// so newlines need an explicit carriage return.
+func (v *view) write(text string) {
+	if v.pty {
+		text = strings.ReplaceAll(text, "\n", "\r\n")
+	}
+	io.WriteString(v.s, text)
+}
+
+func visibleLen(s string) int {
+	return len([]rune(ansiEscape.ReplaceAllString(s, "")))
+}
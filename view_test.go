@@ -0,0 +1,89 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gliderlabs/ssh"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ptySession is a session whose client asked for a PTY of size win and
+// resizes it by sending on winCh.
+type ptySession struct {
+	fakeSession
+	win   ssh.Window
+	winCh chan ssh.Window
+}
+
+func (s *ptySession) Pty() (ssh.Pty, <-chan ssh.Window, bool) {
+	return ssh.Pty{Term: "xterm", Window: s.win}, s.winCh, true
+}
+
+// screen returns the lines drawn since the screen was last cleared, without
+// colours, once it has been cleared clears times.
+func (s *ptySession) screen(t *testing.T, v *view, clears int) []string {
+	const clearScreen = "\033[H\033[2J"
+	var out string
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(10 * time.Millisecond) {
+		v.flash(func() { out = s.out.String() })
+		if strings.Count(out, clearScreen) >= clears {
+			break
+		}
+		require.True(t, time.Now().Before(deadline), "screen was cleared %d times, want %d", strings.Count(out, clearScreen), clears)
+	}
+	out = out[strings.LastIndex(out, clearScreen)+len(clearScreen):]
+	return strings.Split(ansiEscape.ReplaceAllString(out, ""), "\r\n")
+}
+
+// lineWith returns the index of the first line containing text, and the
+// column it starts at.
+func lineWith(lines []string, text string) (int, int) {
+	for i, line := range lines {
+		if col := strings.Index(line, text); col >= 0 {
+			return i, col
+		}
+	}
+	return -1, -1
+}
+
+func TestViewLayout(t *testing.T) {
+	s := &ptySession{win: ssh.Window{Width: 80, Height: 24}, winCh: make(chan ssh.Window)}
+	defer close(s.winCh)
+	v := newView(s)
+	game := NewGame("water")
+	game.Guesses = []string{"tower"}
+	render(v, game)
+
+	// Wide terminals get boxed tiles and a spaced keyboard, each centred.
+	lines := s.screen(t, v, 1)
+	board, col := lineWith(lines, "[t][o][w][e][r]")
+	require.NotEqual(t, -1, board, "%q", lines)
+	assert.Equal(t, (80-15)/2, col)
+	keys, col := lineWith(lines, "q w e r t y u i o p")
+	require.NotEqual(t, -1, keys, "%q", lines)
+	assert.Equal(t, (80-19)/2, col)
+	assert.Greater(t, keys, board+MaxGuesses-1, "keyboard under the board")
+
+	// Narrowing the window redraws the board compact, recentred.
+	s.winCh <- ssh.Window{Width: 24, Height: 30}
+	lines = s.screen(t, v, 2)
+	board, col = lineWith(lines, "tower")
+	require.NotEqual(t, -1, board, "%q", lines)
+	assert.Equal(t, (24-5)/2, col)
+	assert.Equal(t, -1, strings.Index(lines[board], "["), "compact tiles have no boxes")
+	keys, col = lineWith(lines, "qwertyuiop")
+	require.NotEqual(t, -1, keys, "%q", lines)
+	assert.Equal(t, (24-10)/2, col)
+	assert.Greater(t, keys, board+MaxGuesses-1, "keyboard under the board")
+
+	// A window too short for the keyboard leaves it out.
+	s.winCh <- ssh.Window{Width: 80, Height: 10}
+	lines = s.screen(t, v, 3)
+	board, _ = lineWith(lines, "[t][o][w][e][r]")
+	assert.NotEqual(t, -1, board, "%q", lines)
+	keys, _ = lineWith(lines, "q w e r t y u i o p")
+	assert.Equal(t, -1, keys, "%q", lines)
+}
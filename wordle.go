@@ -2,8 +2,9 @@ package main
 
 import (
 	"fmt"
-	"sort"
+	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 const (
@@ -12,7 +13,9 @@ const (
 )
 
 var (
-	ErrGameOver = fmt.Errorf("game over")
+	ErrGameOver    = fmt.Errorf("game over")
+	ErrWordLength  = fmt.Errorf("word must be %d letters", WordLength)
+	ErrInvalidWord = fmt.Errorf("invalid word")
 )
 
 type Game struct {
@@ -22,6 +25,15 @@ type Game struct {
 	Started  time.Time
 	Finished time.Time
 	Won      bool
+	// Lang is the language of the word game; empty means English.
+	Lang string `json:",omitempty"`
+	// Imported games came from a share grid, so their guesses other than
+	// a winning one are unknown.
+	Imported bool `json:",omitempty"`
+	// Clock times a speedrun; daily games have none.
+	Clock *Clock `json:",omitempty"`
+	// Events log the game as it was played; see events.go.
+	Events []Event `json:",omitempty"`
 }
 
 type Games []Game
@@ -40,16 +52,23 @@ func (g *Game) Guess(word string) (error, bool) {
 		return ErrGameOver, false
 	}
 
-	if len(word) != WordLength {
-		return fmt.Errorf("word must be %d letters", WordLength), false
+	word = normalize(g.Lang, word)
+	if utf8.RuneCountInString(word) != WordLength {
+		g.record(eventReject, word, rejectReason(ErrWordLength))
+		return ErrWordLength, false
 	}
 
-	if !allowedGuess(word) {
-		return fmt.Errorf("invalid word %q", word), false
+	if !dictionaryFor(g.Lang).IsAllowed(word) {
+		g.record(eventReject, word, rejectReason(ErrInvalidWord))
+		return fmt.Errorf("%w %q", ErrInvalidWord, word), false
 	}
 
+	g.record(eventGuess, word, "")
 	g.Guesses = append(g.Guesses, word)
 	g.Won = word == g.Answer
+	if g.Clock != nil {
+		g.Clock.split(time.Now())
+	}
 
 	if g.IsDone() {
 		g.Finished = time.Now()
@@ -58,6 +77,51 @@ func (g *Game) Guess(word string) (error, bool) {
 	return nil, g.Won
 }
 
+type letterState int
+
+const (
+	letterUnknown letterState = iota
+	letterAbsent
+	letterPresent
+	letterCorrect
+)
+
+// Score returns the state of each letter of word against the answer: correct
+// if it is in the same spot, present if it is elsewhere in the answer and
+// absent otherwise.
+func (g *Game) Score(word string) []letterState {
+	var (
+		answer = []rune(g.Answer)
+		states = make([]letterState, 0, WordLength)
+	)
+	for i, c := range []rune(word) {
+		switch {
+		case i < len(answer) && answer[i] == c:
+			states = append(states, letterCorrect)
+		case strings.ContainsRune(g.Answer, c):
+			states = append(states, letterPresent)
+		default:
+			states = append(states, letterAbsent)
+		}
+	}
+	return states
+}
+
+// LetterStates returns the best known state of every letter guessed so far.
+func (g *Game) LetterStates() map[rune]letterState {
+	known := make(map[rune]letterState)
+	for _, word := range g.Guesses {
+		letters := []rune(word)
+		for i, state := range g.Score(word) {
+			c := letters[i]
+			if state > known[c] {
+				known[c] = state
+			}
+		}
+	}
+	return known
+}
+
 func (g *Game) IsDone() bool {
 	return g.Won || len(g.Guesses) == MaxGuesses
 }
@@ -66,41 +130,33 @@ func (g *Game) String() string {
 	return g.Render()
 }
 
+// Render draws the board with the classic theme.
 func (g *Game) Render() string {
-	board := ""
-	rowTemplate := "[%v][%v][%v][%v][%v]\n"
-	emptyrow := "[ ][ ][ ][ ][ ]\n"
-
+	var board strings.Builder
 	for _, word := range g.Guesses {
-		letters := []interface{}{}
-		for wordI, c := range word {
-			letter := fmt.Sprintf("[%s]", string(c))
-
-			// Color the letter
-			for answerI, answerC := range g.Answer {
-				if answerC == c {
-					// The letter is in the word
-					if answerI == wordI {
-						// And in the correct spot.
-						// Green
-						letter = fmt.Sprintf("\033[32;0;0m%s\033[0m", string(c))
-					} else {
-						// Yellow
-						letter = fmt.Sprintf("\033[33;0;0m%s\033[0m", string(c))
-					}
-				}
-			}
-
-			letters = append(letters, letter)
+		letters := []rune(word)
+		for i, state := range g.Score(word) {
+			board.WriteString(classicTheme.Tile(state, string(letters[i]), false))
 		}
-		board += fmt.Sprintf(rowTemplate, letters...)
+		board.WriteString("\n")
 	}
 
 	for i := len(g.Guesses); i < MaxGuesses; i++ {
-		board += emptyrow
+		board.WriteString(strings.Repeat(classicTheme.Tile(letterUnknown, " ", false), WordLength) + "\n")
 	}
 
-	return board
+	return board.String()
+}
+
+// InLang returns the games played in lang, in the same order.
+func (games Games) InLang(lang string) Games {
+	var matched Games
+	for _, g := range games {
+		if g.Lang == lang || g.Lang == "" && lang == defaultLocale {
+			matched = append(matched, g)
+		}
+	}
+	return matched
 }
 
 func (games Games) Played() int {
@@ -117,38 +173,15 @@ func (games Games) WinPercent() int {
 	return int(float32(wins) / float32(len(games)) * 100)
 }
 
-func (games Games) CurrentStreak() int {
-	streak := 0
-	for i := len(games) - 1; i >= 0; i-- {
-		if games[i].Won {
-			streak += 1
-		} else {
-			break
-		}
-	}
-	return streak
+// CurrentStreak returns the number of wins on consecutive days up to now,
+// whatever order games are in.
+func (games Games) CurrentStreak(now time.Time) int {
+	return newPlayerStats(games, StreakConfig{}).Streak(now)
 }
 
+// MaxStreak returns the most wins there have been on consecutive days.
 func (games Games) MaxStreak() int {
-	var (
-		streaks = []int{0}
-		streak  = 0
-	)
-	for _, game := range games {
-		if game.Won {
-			streak += 1
-		} else {
-			streaks = append(streaks, streak)
-			streak = 0
-		}
-	}
-
-	if streak != 0 {
-		streaks = append(streaks, streak)
-	}
-
-	sort.Ints(streaks)
-	return streaks[len(streaks)-1]
+	return newPlayerStats(games, StreakConfig{}).MaxStreak
 }
 
 func (games Games) GuessDistribution() []int {
@@ -2,6 +2,7 @@ package main
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -46,78 +47,99 @@ func TestWinPercent(t *testing.T) {
 	assert.Equal(t, 50, games.WinPercent())
 }
 
+// dailyGames returns the games of a player who won (W), lost (L) or missed
+// (.) the puzzle on consecutive days ending on last, newest first like
+// ListGames.
+func dailyGames(last time.Time, days string) Games {
+	var games Games
+	for i := range days {
+		game := Game{Answer: "water", Started: last.AddDate(0, 0, i-len(days)+1)}
+		switch days[i] {
+		case 'W':
+			game.Guesses, game.Won = []string{"water"}, true
+		case 'L':
+			game.Guesses = []string{"teeth", "salad", "grape", "chili", "onion", "treat"}
+		default:
+			continue
+		}
+		games = append(Games{game}, games...)
+	}
+	return games
+}
+
 func TestCurrentStreak(t *testing.T) {
-	games := Games{
-		{Answer: "water", Won: true},
-		{Answer: "waste", Won: false},
-		{Answer: "treat", Won: true},
-		{Answer: "onion", Won: true},
+	today := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	var tests = []struct {
+		Name   string
+		Days   string
+		Last   time.Time
+		Streak int
+	}{
+		{"won today", "WLWW", today, 2},
+		{"not played today yet", "WLWW", today.AddDate(0, 0, -1), 2},
+		{"missed yesterday", "WLWW", today.AddDate(0, 0, -2), 0},
+		{"missed a day", "WWW.WW", today, 2},
+		{"lost today", "WWWL", today, 0},
 	}
 
-	assert.Equal(t, 2, games.CurrentStreak())
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert.Equal(t, tt.Streak, dailyGames(tt.Last, tt.Days).CurrentStreak(today))
+		})
+	}
 }
 
 func TestMaxStreak(t *testing.T) {
+	today := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
 	var tests = []struct {
 		Name      string
-		Games     Games
+		Days      string
 		MaxStreak int
 	}{
-		{
-			Name:      "one game win",
-			Games:     Games{{Answer: "water", Won: true}},
-			MaxStreak: 1,
-		},
-		{
-			Name:      "one game lose",
-			Games:     Games{{Answer: "water", Won: false}},
-			MaxStreak: 0,
-		},
-		{
-			Name: "many games",
-			Games: Games{
-				{Answer: "water", Won: true},
-				{Answer: "waste", Won: false},
-				{Answer: "treat", Won: true},
-				{Answer: "onion", Won: true},
-				{Answer: "treat", Won: true},
-				{Answer: "treat", Won: true},
-				{Answer: "onion", Won: true},
-				{Answer: "onion", Won: true},
-				{Answer: "waste", Won: false},
-				{Answer: "treat", Won: true},
-				{Answer: "onion", Won: true},
-				{Answer: "onion", Won: true},
-			},
-			MaxStreak: 6,
-		},
-		{
-			Name: "many games 2",
-			Games: Games{
-				{Answer: "water", Won: true},
-				{Answer: "waste", Won: false},
-				{Answer: "treat", Won: true},
-				{Answer: "onion", Won: true},
-				{Answer: "treat", Won: true},
-				{Answer: "treat", Won: true},
-				{Answer: "onion", Won: true},
-				{Answer: "waste", Won: false},
-				{Answer: "treat", Won: true},
-				{Answer: "onion", Won: true},
-				{Answer: "onion", Won: true},
-				{Answer: "treat", Won: true},
-				{Answer: "treat", Won: true},
-				{Answer: "onion", Won: true},
-				{Answer: "onion", Won: true},
-			},
-			MaxStreak: 7,
-		},
+		{"one game win", "W", 1},
+		{"one game lose", "L", 0},
+		{"many games", "WLWWWWWWLWWW", 6},
+		{"many games 2", "WLWWWWWLWWWWWWW", 7},
+		{"missed days", "WWW.WW.WWW..W", 3},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.Name, func(t *testing.T) {
-			assert.Equal(t, tt.MaxStreak, tt.Games.MaxStreak())
+			assert.Equal(t, tt.MaxStreak, dailyGames(today, tt.Days).MaxStreak())
 		})
 	}
+}
+
+func TestScore(t *testing.T) {
+	game := NewGame("water")
+
+	assert.Equal(t,
+		[]letterState{letterAbsent, letterCorrect, letterPresent, letterAbsent, letterPresent},
+		game.Score("harow"),
+	)
+	assert.Equal(t,
+		[]letterState{letterCorrect, letterCorrect, letterCorrect, letterCorrect, letterCorrect},
+		game.Score("water"),
+	)
+}
+
+func TestLetterStates(t *testing.T) {
+	game := NewGame("water")
+	game.Guesses = []string{"tepid", "wheat"}
+
+	known := game.LetterStates()
+	assert.Equal(t, letterCorrect, known['w'])
+	assert.Equal(t, letterPresent, known['t'])
+	assert.Equal(t, letterPresent, known['e'])
+	assert.Equal(t, letterAbsent, known['d'])
+	assert.Equal(t, letterUnknown, known['z'])
+}
+
+func TestRender(t *testing.T) {
+	game := NewGame("water")
+	game.Guesses = []string{"tower"}
 
+	board := game.Render()
+	assert.Contains(t, board, "\033[33m[t]\033[0m[o]\033[33m[w]\033[0m\033[32m[e]\033[0m\033[32m[r]\033[0m\n")
+	assert.NotContains(t, board, ";0;0m")
 }
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// Word list files hold one word of WordLength letters per line, in UTF-8.
+// Blank lines and lines starting with # are ignored. The answers file lists
+// the daily answers in the order they are played; the allowed file lists
+// every word accepted as a guess and must include every answer.
+//
+// A word list directory holds a subdirectory per language code, each with an
+// answers.txt and an allowed.txt:
+//
+//	words/
+//	    SHA256SUMS
+//	    en/answers.txt
+//	    en/allowed.txt
+//	    es/answers.txt
+//	    es/allowed.txt
+//
+// If the directory has a SHA256SUMS file, in the format written by
+// `sha256sum */*.txt`, every word list must be listed in it and match.
+
+const checksumFile = "SHA256SUMS"
+
+// loadDictionaries builds every dictionary the config asks for: the embedded
+// English lists, then the word list directory, then individual files.
+func loadDictionaries(cfg *Config) (map[string]*dictionary, error) {
+	dicts := map[string]*dictionary{
+		defaultLocale: embeddedDictionary(),
+	}
+
+	if cfg.Words.Dir != "" {
+		sums, err := readChecksums(filepath.Join(cfg.Words.Dir, checksumFile))
+		if err != nil {
+			return nil, err
+		}
+		entries, err := os.ReadDir(cfg.Words.Dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+			lang := entry.Name()
+			if _, ok := catalogs[lang]; !ok {
+				return nil, fmt.Errorf("%s: unknown language %q", cfg.Words.Dir, lang)
+			}
+			words := WordsConfig{
+				Answers: filepath.Join(cfg.Words.Dir, lang, "answers.txt"),
+				Allowed: filepath.Join(cfg.Words.Dir, lang, "allowed.txt"),
+			}
+			dict, err := loadDictionary(lang, words, sums)
+			if err != nil {
+				return nil, err
+			}
+			dicts[lang] = dict
+		}
+	}
+
+	if cfg.Words.Answers != "" {
+		dict, err := loadDictionary(defaultLocale, cfg.Words, nil)
+		if err != nil {
+			return nil, err
+		}
+		dicts[defaultLocale] = dict
+	}
+	for lang, words := range cfg.Languages {
+		dict, err := loadDictionary(lang, words, nil)
+		if err != nil {
+			return nil, err
+		}
+		dicts[lang] = dict
+	}
+
+	return dicts, nil
+}
+
+// loadDictionary builds the dictionary for lang from the word list files,
+// checking them against sums when it is not nil.
+func loadDictionary(lang string, words WordsConfig, sums map[string]string) (*dictionary, error) {
+	answers, err := loadWordList(words.Answers, sums)
+	if err != nil {
+		return nil, err
+	}
+	allowed, err := loadWordList(words.Allowed, sums)
+	if err != nil {
+		return nil, err
+	}
+
+	accepted := make(map[string]bool, len(allowed))
+	for _, word := range allowed {
+		accepted[normalize(lang, word)] = true
+	}
+	for _, word := range answers {
+		if !accepted[normalize(lang, word)] {
+			return nil, fmt.Errorf("%s: answer %q is not in %s", words.Answers, word, words.Allowed)
+		}
+	}
+
+	return newDictionary(lang, answers, allowed), nil
+}
+
+// loadWordList reads a word list file. When sums is not nil the file must be
+// listed in it, by its path relative to the word list directory.
+func loadWordList(path string, sums map[string]string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if sums != nil {
+		name := filepath.ToSlash(filepath.Join(filepath.Base(filepath.Dir(path)), filepath.Base(path)))
+		want, ok := sums[name]
+		if !ok {
+			return nil, fmt.Errorf("%s: not listed in %s", path, checksumFile)
+		}
+		got := sha256.Sum256(data)
+		if hex.EncodeToString(got[:]) != want {
+			return nil, fmt.Errorf("%s: checksum mismatch", path)
+		}
+	}
+
+	var (
+		words   []string
+		scanner = bufio.NewScanner(bytes.NewReader(data))
+		line    = 0
+	)
+	for scanner.Scan() {
+		line++
+		word := strings.ToLower(strings.TrimSpace(scanner.Text()))
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		if !utf8.ValidString(word) {
+			return nil, fmt.Errorf("%s:%d: not valid UTF-8", path, line)
+		}
+		if utf8.RuneCountInString(word) != WordLength {
+			return nil, fmt.Errorf("%s:%d: %q is not %d letters", path, line, word, WordLength)
+		}
+		words = append(words, word)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("%s: no words", path)
+	}
+
+	return words, nil
+}
+
+// readChecksums parses a sha256sum file into checksums by file name. It
+// returns nil if there is no such file.
+func readChecksums(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sums := make(map[string]string)
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: want \"<sha256>  <file>\"", path, i+1)
+		}
+		// sha256sum marks files read in binary mode with a leading *.
+		sums[strings.TrimPrefix(fields[1], "*")] = strings.ToLower(fields[0])
+	}
+	return sums, nil
+}
+
+// reloadWords loads the word lists again and swaps them in, keeping the
+// current ones if any list is invalid.
+func reloadWords(cfg *Config) {
+	dicts, err := loadDictionaries(cfg)
+	if err != nil {
+		logs.Error("reload_words_failed", "err", err)
+		return
+	}
+	setDictionaries(dicts)
+	logDictionaries(dicts, "words_reloaded")
+}
+
+// logDictionaries logs the size and checksum of each dictionary, never the
+// words themselves.
+func logDictionaries(dicts map[string]*dictionary, event string) {
+	for _, lang := range localeNames() {
+		if d, ok := dicts[lang]; ok {
+			logs.Info(event, "lang", lang, "answers", len(d.Answers), "allowed", len(d.allowed), "sha256", d.Checksum[:12])
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeWordDir writes a word list directory with the given files, relative
+// to it, and returns its path.
+func writeWordDir(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return dir
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestLoadDictionaries(t *testing.T) {
+	var (
+		answers = "# answers\nCrane\nslate\n"
+		allowed = "crane\nslate\n\nadieu\n"
+	)
+	dir := writeWordDir(t, map[string]string{
+		"es/answers.txt": answers,
+		"es/allowed.txt": allowed,
+		"SHA256SUMS": fmt.Sprintf("%s  es/answers.txt\n%s *es/allowed.txt\n",
+			sha256Hex(answers), sha256Hex(allowed)),
+	})
+
+	cfg := defaultConfig()
+	cfg.Words.Dir = dir
+	dicts, err := loadDictionaries(cfg)
+	require.NoError(t, err)
+
+	require.Contains(t, dicts, "es")
+	assert.Equal(t, []string{"crane", "slate"}, dicts["es"].Answers)
+	assert.True(t, dicts["es"].IsAllowed("adieu"))
+	assert.False(t, dicts["es"].IsAllowed("water"))
+	assert.Equal(t, embeddedDictionary().Checksum, dicts[defaultLocale].Checksum)
+}
+
+func TestLoadDictionariesInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{"answer not allowed", map[string]string{
+			"en/answers.txt": "crane\n",
+			"en/allowed.txt": "slate\n",
+		}, `answer "crane" is not in`},
+		{"wrong length", map[string]string{
+			"en/answers.txt": "cranes\n",
+			"en/allowed.txt": "cranes\n",
+		}, "is not 5 letters"},
+		{"checksum mismatch", map[string]string{
+			"en/answers.txt": "crane\n",
+			"en/allowed.txt": "crane\n",
+			"SHA256SUMS": fmt.Sprintf("%s  en/answers.txt\n%s  en/allowed.txt\n",
+				sha256Hex("slate\n"), sha256Hex("crane\n")),
+		}, "checksum mismatch"},
+		{"not listed", map[string]string{
+			"en/answers.txt": "crane\n",
+			"en/allowed.txt": "crane\n",
+			"SHA256SUMS":     sha256Hex("crane\n") + "  en/answers.txt\n",
+		}, "not listed in SHA256SUMS"},
+		{"unknown language", map[string]string{
+			"xx/answers.txt": "crane\n",
+			"xx/allowed.txt": "crane\n",
+		}, `unknown language "xx"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaultConfig()
+			cfg.Words.Dir = writeWordDir(t, tt.files)
+			_, err := loadDictionaries(cfg)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
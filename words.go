@@ -1,40 +1,131 @@
 package main
 
 import (
-	"strings"
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"sync"
 	"time"
 )
 
-func wordOfTheDay() string {
+// dictionary is the word game for one language: the answers, in the order
+// they are played, and every word accepted as a guess.
+type dictionary struct {
+	Lang    string
+	Answers []string
+	// Checksum identifies the word lists in logs without revealing them.
+	Checksum string
+	allowed  map[string]bool
+}
+
+// dictionaries holds a dictionary per language code. English is built in;
+// others are loaded from the word lists in the config and swapped as a whole
+// when they are reloaded.
+var (
+	dictionariesMu sync.RWMutex
+	dictionaries   = map[string]*dictionary{
+		defaultLocale: embeddedDictionary(),
+	}
+)
+
+// embeddedDictionary builds the English dictionary compiled into the binary.
+func embeddedDictionary() *dictionary {
+	return newDictionary(defaultLocale, WORDS, ALLOWEDGUESSES)
+}
+
+// newDictionary normalizes the word lists for lang. Answers are always
+// allowed as guesses.
+func newDictionary(lang string, answers, allowed []string) *dictionary {
+	var (
+		d = &dictionary{
+			Lang:    lang,
+			Answers: make([]string, 0, len(answers)),
+			allowed: make(map[string]bool, len(answers)+len(allowed)),
+		}
+		sum = sha256.New()
+	)
+	for _, word := range answers {
+		word = normalize(lang, word)
+		d.Answers = append(d.Answers, word)
+		d.allowed[word] = true
+		sum.Write([]byte(word + "\n"))
+	}
+	sum.Write([]byte("\n"))
+	for _, word := range allowed {
+		word = normalize(lang, word)
+		d.allowed[word] = true
+		sum.Write([]byte(word + "\n"))
+	}
+	d.Checksum = hex.EncodeToString(sum.Sum(nil))
+	return d
+}
+
+// dictionaryFor returns the dictionary for lang, falling back to English.
+func dictionaryFor(lang string) *dictionary {
+	dictionariesMu.RLock()
+	defer dictionariesMu.RUnlock()
+	if d, ok := dictionaries[lang]; ok {
+		return d
+	}
+	return dictionaries[defaultLocale]
+}
+
+// hasDictionary reports whether lang has word lists of its own.
+func hasDictionary(lang string) bool {
+	dictionariesMu.RLock()
+	defer dictionariesMu.RUnlock()
+	_, ok := dictionaries[lang]
+	return ok
+}
+
+// setDictionaries replaces every dictionary at once. Games already started
+// keep their answer but check guesses against the new lists.
+func setDictionaries(dicts map[string]*dictionary) {
+	dictionariesMu.Lock()
+	defer dictionariesMu.Unlock()
+	dictionaries = dicts
+}
+
+// IsAllowed reports whether word, already normalized, is a valid guess.
+func (d *dictionary) IsAllowed(word string) bool {
+	return d.allowed[word]
+}
+
+func (d *dictionary) WordOfTheDay(now time.Time) string {
+	return d.Answers[d.dayIndex(now)]
+}
+
+// dayIndex returns the index in the answers of the word of the day of now.
+func (d *dictionary) dayIndex(now time.Time) int {
 	// WORDS is the official wordle wordlist, in order.
 	// To determine the word of the day, find the index of "ultra"
 	// (word on 2/12/2022) and calculate the offset from today.
 	// This means the word of the day will change in UTC time, not
 	// the user's timezone, but that's the best we can do for now.
-	const layout = "2006-Jan-02"
-	ultraDate, _ := time.Parse(layout, "2022-Feb-12")
-	ultraIndex := 0
-	for i, word := range WORDS {
+	// Other word lists simply start on the same day.
+	// Days before it count down from the end of the list.
+	days := int(math.Floor(now.Sub(ultraDate).Hours() / 24))
+	n := len(d.Answers)
+	return ((d.ultraIndex()+days)%n + n) % n
+}
+
+// ultraDate is the day "ultra" was the answer of the official game.
+var ultraDate = time.Date(2022, time.February, 12, 0, 0, 0, 0, time.UTC)
+
+// ultraIndex returns the index of "ultra" in the answers, or 0 for lists
+// without it.
+func (d *dictionary) ultraIndex() int {
+	for i, word := range d.Answers {
 		if word == "ultra" {
-			ultraIndex = i
+			return i
 		}
 	}
-
-	now := time.Now()
-	days := now.Sub(ultraDate).Hours() / 24
-	todaysIndex := ultraIndex + int(days)
-
-	return WORDS[todaysIndex]
+	return 0
 }
 
-func allowedGuess(word string) bool {
-	word = strings.ToLower(word)
-	for _, w := range append(WORDS, ALLOWEDGUESSES...) {
-		if w == word {
-			return true
-		}
-	}
-	return false
+// FirstDay returns the day the first answer of the list was played.
+func (d *dictionary) FirstDay() time.Time {
+	return ultraDate.AddDate(0, 0, -d.ultraIndex())
 }
 
 // https://gist.github.com/cfreshman/cdcdf777450c5b5301e439061d29694c